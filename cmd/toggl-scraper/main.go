@@ -7,11 +7,15 @@ import (
     "net/http"
     "os"
     "os/signal"
+    "path/filepath"
+    "sort"
     "syscall"
     "time"
 
+    "toggl-scraper/internal/adapter/file"
     "toggl-scraper/internal/app"
     "toggl-scraper/internal/config"
+    "toggl-scraper/internal/domain"
 )
 
 func main() {
@@ -19,9 +23,13 @@ func main() {
     once := flag.Bool("once", false, "Run a single sync and exit")
     interval := flag.Duration("interval", 15*time.Minute, "Sync interval when not running once")
     daily := flag.Bool("daily", false, "Run at local midnight each day (uses SYNC_TZ, default UTC)")
-    from := flag.String("from", "", "ISO8601 start time (optional, default: now - 24h)")
-    to := flag.String("to", "", "ISO8601 end time (optional, default: now)")
+    from := flag.String("from", "", "ISO8601 start time; with --to, overrides incremental sync (default: now - 24h)")
+    to := flag.String("to", "", "ISO8601 end time; with --from, overrides incremental sync (default: now)")
     httpAddr := flag.String("http", "", "Start HTTP trigger server on address (e.g., :8080)")
+    export := flag.String("export", "", "Export entries in the --from/--to window as ndjson or csv and exit")
+    exportSource := flag.String("export-source", app.ExportFromToggl, "Where --export reads entries: toggl or db (the primary SINK)")
+    out := flag.String("out", "-", "Output file for --export; - writes to stdout")
+    asOf := flag.String("as-of", "", "With --export-source db, export entries as stored at this RFC3339 time or the end of this YYYY-MM-DD day")
     verbose := flag.Bool("v", false, "Enable verbose logging")
     flag.Parse()
 
@@ -30,7 +38,12 @@ func main() {
     if *verbose {
         level = slog.LevelDebug
     }
-    handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+    // Keep stdout clean for exported data.
+    logOut := os.Stdout
+    if *export != "" {
+        logOut = os.Stderr
+    }
+    handler := slog.NewTextHandler(logOut, &slog.HandlerOptions{Level: level})
     logger := slog.New(handler)
     slog.SetDefault(logger)
 
@@ -47,8 +60,22 @@ func main() {
         toTime   time.Time
     )
     now := time.Now().UTC()
-    toTime = parseEnd(*to, now, logger)
-    fromTime = parseStart(*from, toTime.Add(-24*time.Hour), logger)
+    toTime = parseEnd("--to", *to, now, logger)
+    fromTime = parseStart("--from", *from, toTime.Add(-24*time.Hour), logger)
+    // An explicit window overrides the incremental watermark.
+    explicitWindow := *from != "" || *to != ""
+
+    if *export != "" {
+        opts := app.ExportOptions{Source: *exportSource, From: fromTime, To: toTime}
+        if *asOf != "" {
+            opts.AsOf = parseEnd("--as-of", *asOf, time.Time{}, logger)
+        }
+        if err := runExport(logger, cfg, *export, opts, *out); err != nil {
+            logger.Error("export failed", slog.String("error", err.Error()))
+            os.Exit(1)
+        }
+        return
+    }
 
     // App
     application, err := app.New(logger, cfg)
@@ -57,6 +84,13 @@ func main() {
         os.Exit(1)
     }
 
+    runInitial := func(ctx context.Context, trigger domain.SyncTrigger) (domain.SyncRun, error) {
+        if explicitWindow {
+            return application.RunOnce(ctx, trigger, fromTime, toTime)
+        }
+        return application.RunIncremental(ctx, trigger)
+    }
+
     // Context with signal handling
     ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
     defer stop()
@@ -64,7 +98,11 @@ func main() {
     // Optional HTTP trigger server
     var httpSrv *http.Server
     if *httpAddr != "" {
-        httpSrv = application.HTTPServer(*httpAddr)
+        httpSrv, err = application.HTTPServer(*httpAddr)
+        if err != nil {
+            logger.Error("failed to start http server", slog.String("error", err.Error()))
+            os.Exit(1)
+        }
         go func() {
             logger.Info("starting http server", slog.String("addr", *httpAddr))
             if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -80,11 +118,12 @@ func main() {
     }
 
     if *once {
-        if err := application.RunOnce(ctx, fromTime, toTime); err != nil {
+        run, err := runInitial(ctx, domain.TriggerCLI)
+        if err != nil {
             logger.Error("sync failed", slog.String("error", err.Error()))
             os.Exit(1)
         }
-        logger.Info("sync completed")
+        logRun(logger, "sync completed", run)
         return
     }
 
@@ -107,13 +146,12 @@ func main() {
                 logger.Info("shutting down")
                 return
             case <-time.After(dur):
-                // Define window as [midnight-24h, midnight) in local tz, expressed in UTC
-                endUTC := next.UTC()
-                startUTC := endUTC.Add(-24 * time.Hour)
-                if err := application.RunOnce(ctx, startUTC, endUTC); err != nil {
+                // Pick up everything changed since the last run, including
+                // edits to entries from earlier days.
+                if run, err := application.RunIncremental(ctx, domain.TriggerDaily); err != nil {
                     logger.Error("daily sync failed", slog.String("error", err.Error()))
                 } else {
-                    logger.Info("daily sync completed", slog.Time("from", startUTC), slog.Time("to", endUTC))
+                    logRun(logger, "daily sync completed", run)
                 }
             }
         }
@@ -124,8 +162,10 @@ func main() {
     defer ticker.Stop()
     logger.Info("starting periodic sync", slog.Duration("interval", *interval))
     // Kick off immediately
-    if err := application.RunOnce(ctx, fromTime, toTime); err != nil {
+    if run, err := runInitial(ctx, domain.TriggerInterval); err != nil {
         logger.Error("initial sync failed", slog.String("error", err.Error()))
+    } else {
+        logRun(logger, "initial sync completed", run)
     }
     for {
         select {
@@ -133,18 +173,98 @@ func main() {
             logger.Info("shutting down")
             return
         case <-ticker.C:
-            end := time.Now().UTC()
-            start := end.Add(-24 * time.Hour)
-            if err := application.RunOnce(ctx, start, end); err != nil {
+            if run, err := application.RunIncremental(ctx, domain.TriggerInterval); err != nil {
                 logger.Error("periodic sync failed", slog.String("error", err.Error()))
+            } else {
+                logRun(logger, "periodic sync completed", run)
             }
         }
     }
 }
 
-// parseStart parses a start boundary that may be RFC3339 or YYYY-MM-DD.
-// If empty, defaultVal is returned.
-func parseStart(val string, defaultVal time.Time, log *slog.Logger) time.Time {
+// runExport writes the entries selected by opts to out ("-" for stdout) in
+// format. A file is written to a temporary name first so a failed export
+// leaves no partial file behind.
+func runExport(log *slog.Logger, cfg config.Config, format string, opts app.ExportOptions, out string) error {
+    f, err := file.ParseFormat(format)
+    if err != nil {
+        return err
+    }
+    opts.Format = f
+    ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+    defer stop()
+    if out == "-" {
+        return app.Export(ctx, log, cfg, opts, os.Stdout)
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+".*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    if err := tmp.Chmod(0o644); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := app.Export(ctx, log, cfg, opts, tmp); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), out)
+}
+
+// logRun logs a completed run with per-entity counts, e.g.
+// entries.inserted=3 entries.unchanged=40 projects.unchanged=12. With
+// several sinks each one's counts follow under sinks.<name>.
+func logRun(log *slog.Logger, msg string, run domain.SyncRun) {
+    attrs := []any{slog.Bool("changed", run.Changed())}
+    attrs = append(attrs, countsAttrs(run.Counts)...)
+    if len(run.Sinks) > 0 {
+        names := make([]string, 0, len(run.Sinks))
+        for name := range run.Sinks {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        sinks := make([]any, 0, len(names))
+        for _, name := range names {
+            r := run.Sinks[name]
+            sinkAttrs := countsAttrs(r.Counts)
+            if r.Error != "" {
+                sinkAttrs = append(sinkAttrs, slog.String("error", r.Error))
+            }
+            sinks = append(sinks, slog.Group(name, sinkAttrs...))
+        }
+        attrs = append(attrs, slog.Group("sinks", sinks...))
+    }
+    log.Info(msg, attrs...)
+}
+
+// countsAttrs returns one log group per entity, in name order.
+func countsAttrs(counts map[string]domain.SyncCounts) []any {
+    entities := make([]string, 0, len(counts))
+    for entity := range counts {
+        entities = append(entities, entity)
+    }
+    sort.Strings(entities)
+    attrs := make([]any, 0, len(entities))
+    for _, entity := range entities {
+        c := counts[entity]
+        attrs = append(attrs, slog.Group(entity,
+            slog.Int("fetched", c.Fetched),
+            slog.Int("inserted", c.Inserted),
+            slog.Int("updated", c.Updated),
+            slog.Int("unchanged", c.Unchanged),
+            slog.Int("deleted", c.Deleted),
+        ))
+    }
+    return attrs
+}
+
+// parseStart parses the start boundary given in flag name, which may be
+// RFC3339 or YYYY-MM-DD. If empty, defaultVal is returned.
+func parseStart(name, val string, defaultVal time.Time, log *slog.Logger) time.Time {
     if val == "" {
         return defaultVal
     }
@@ -155,15 +275,16 @@ func parseStart(val string, defaultVal time.Time, log *slog.Logger) time.Time {
     if d, err := time.Parse("2006-01-02", val); err == nil {
         return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
     }
-    log.Error("invalid --from, expected RFC3339 or YYYY-MM-DD")
+    log.Error("invalid " + name + ", expected RFC3339 or YYYY-MM-DD")
     os.Exit(1)
     return time.Time{}
 }
 
-// parseEnd parses an end boundary that may be RFC3339 or YYYY-MM-DD.
+// parseEnd parses the end boundary given in flag name, which may be RFC3339
+// or YYYY-MM-DD.
 // Date-only form is treated as inclusive by converting to next-day 00:00 UTC.
 // If empty, defaultVal is returned.
-func parseEnd(val string, defaultVal time.Time, log *slog.Logger) time.Time {
+func parseEnd(name, val string, defaultVal time.Time, log *slog.Logger) time.Time {
     if val == "" {
         return defaultVal
     }
@@ -174,7 +295,7 @@ func parseEnd(val string, defaultVal time.Time, log *slog.Logger) time.Time {
         next := d.Add(24 * time.Hour)
         return time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
     }
-    log.Error("invalid --to, expected RFC3339 or YYYY-MM-DD")
+    log.Error("invalid " + name + ", expected RFC3339 or YYYY-MM-DD")
     os.Exit(1)
     return time.Time{}
 }
@@ -5,7 +5,8 @@ set -euo pipefail
 # Optionally expects GHCR_USERNAME and GHCR_TOKEN env vars to be set for private images.
 # Optional env vars:
 #   HTTP_PORT (default: 8085)
-#   APP_ARGS (default: "--http=:${HTTP_PORT}")
+#   APP_ARGS (default: "--http=:${HTTP_PORT}"; the env file then needs HTTP_SYNC_TOKENS,
+#   HTTP_TOKENS_FILE or HTTP_AUTH=none, or the HTTP server refuses to start)
 
 IMAGE=${1:?image ref required}
 ENV_FILE=${2:?env file path required}
@@ -0,0 +1,74 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	msql "toggl-scraper/internal/adapter/mysql"
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/migrate"
+)
+
+// BenchmarkSyncEntries_MySQL measures a 10k entry backfill into a MySQL
+// container at different batch sizes. Every iteration writes fresh IDs so
+// all rows are inserts.
+func BenchmarkSyncEntries_MySQL(b *testing.B) {
+	ctx := context.Background()
+	dsn := startMySQL(b, ctx)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := migrate.Run(ctx, migrate.MySQL, dsn, logger); err != nil {
+		b.Fatalf("migrate: %v", err)
+	}
+
+	const n = 10000
+	var nextID int64
+	for _, size := range []int{1, 100, 500, 1000} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			sink, err := msql.NewClient(ctx, dsn, size, nil, logger)
+			if err != nil {
+				b.Fatalf("mysql client: %v", err)
+			}
+			defer sink.Close()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				entries := benchEntries(nextID, n)
+				nextID += n
+				b.StartTimer()
+				if _, err := sink.SyncEntries(ctx, entries); err != nil {
+					b.Fatalf("sync entries: %v", err)
+				}
+			}
+			b.ReportMetric(float64(n*b.N)/b.Elapsed().Seconds(), "entries/s")
+		})
+	}
+}
+
+func benchEntries(firstID int64, n int) []domain.TimeEntry {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	projectID := int64(1)
+	workspaceID := int64(1)
+	out := make([]domain.TimeEntry, n)
+	for i := range out {
+		s := start.Add(time.Duration(i) * time.Hour)
+		stop := s.Add(45 * time.Minute)
+		out[i] = domain.TimeEntry{
+			ID:          firstID + int64(i) + 1,
+			Description: "bench",
+			ProjectID:   &projectID,
+			WorkspaceID: &workspaceID,
+			Tags:        []string{"bench"},
+			TagIDs:      []int64{1},
+			Start:       s,
+			Stop:        &stop,
+			DurationSec: 2700,
+			At:          stop,
+		}
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"toggl-scraper/internal/adapter/fanout"
+	"toggl-scraper/internal/adapter/sqlite"
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/migrate"
+	"toggl-scraper/internal/usecase"
+)
+
+// failingSink rejects every write.
+type failingSink struct{}
+
+var errSinkDown = errors.New("sink down")
+
+func (failingSink) SyncEntries(context.Context, []domain.TimeEntry) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func (failingSink) MarkMissingDeleted(context.Context, time.Time, time.Time, int64, []int64) (int, error) {
+	return 0, errSinkDown
+}
+
+func (failingSink) SyncProjects(context.Context, []domain.Project) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func (failingSink) SyncClients(context.Context, []domain.Client) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func (failingSink) SyncTags(context.Context, []domain.Tag) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func (failingSink) SyncTasks(context.Context, []domain.Task) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func (failingSink) SyncUsers(context.Context, []domain.User) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func (failingSink) SyncGroups(context.Context, []domain.Group) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, errSinkDown
+}
+
+func TestSync_FanOutToSeveralSinks(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	primary := openSQLiteStore(t, ctx, filepath.Join(dir, "primary.db"), logger)
+	archive := openSQLiteStore(t, ctx, filepath.Join(dir, "archive.db"), logger)
+
+	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	fake := fakeToggl{
+		entries:  []domain.TimeEntry{{ID: 1, Description: "fan-out", Start: start, Stop: &stop, DurationSec: 3600}},
+		projects: []domain.Project{{ID: 5, WorkspaceID: 1, Name: "P", Active: true, At: start}},
+	}
+
+	// An optional sink's failure is reported but does not fail the run.
+	sink, err := fanout.New(logger,
+		fanout.Target{Name: "primary", Sink: primary, Policy: fanout.Required},
+		fanout.Target{Name: "archive", Sink: archive, Policy: fanout.Required},
+		fanout.Target{Name: "flaky", Sink: failingSink{}, Policy: fanout.Optional},
+	)
+	if err != nil {
+		t.Fatalf("fanout: %v", err)
+	}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink, Runs: primary}
+	run, err := uc.Run(ctx, domain.TriggerCLI, start.Add(-time.Hour), start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+	if got := run.Counts["entries"].Inserted; got != 1 {
+		t.Fatalf("expected 1 inserted entry in run counts, got %d", got)
+	}
+	for _, name := range []string{"primary", "archive"} {
+		r := run.Sinks[name]
+		if r.Error != "" || r.Counts["entries"].Inserted != 1 || r.Counts["projects"].Inserted != 1 {
+			t.Fatalf("expected %s to insert 1 entry and 1 project, got %+v", name, r)
+		}
+	}
+	if run.Sinks["flaky"].Error == "" {
+		t.Fatalf("expected flaky sink error in run summary, got %+v", run.Sinks["flaky"])
+	}
+
+	// A required sink's failure fails the run.
+	sink, err = fanout.New(logger,
+		fanout.Target{Name: "primary", Sink: primary, Policy: fanout.Required},
+		fanout.Target{Name: "flaky", Sink: failingSink{}, Policy: fanout.Required},
+	)
+	if err != nil {
+		t.Fatalf("fanout: %v", err)
+	}
+	uc.Sink = sink
+	run, err = uc.Run(ctx, domain.TriggerCLI, start.Add(-time.Hour), start.Add(4*time.Hour))
+	if !errors.Is(err, errSinkDown) {
+		t.Fatalf("expected required sink failure, got %v", err)
+	}
+	if run.Sinks["primary"].Error != "" {
+		t.Fatalf("expected primary to succeed, got %+v", run.Sinks["primary"])
+	}
+}
+
+// openSQLiteStore migrates and opens a SQLite sink at path.
+func openSQLiteStore(t *testing.T, ctx context.Context, path string, logger *slog.Logger) *sqlite.Client {
+	t.Helper()
+	if err := migrate.Run(ctx, migrate.SQLite, path, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	c, err := sqlite.NewClient(ctx, path, sqlite.DefaultBatchSize, nil, logger)
+	if err != nil {
+		t.Fatalf("sqlite client: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
@@ -0,0 +1,242 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"toggl-scraper/internal/adapter/file"
+	"toggl-scraper/internal/app"
+	"toggl-scraper/internal/config"
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/migrate"
+	"toggl-scraper/internal/usecase"
+)
+
+func TestFileSink_PartitionsByDay(t *testing.T) {
+	for _, format := range []file.Format{file.NDJSON, file.CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			dir := t.TempDir()
+			sink, err := file.NewSink(dir+"?format="+string(format), logger)
+			if err != nil {
+				t.Fatalf("file sink: %v", err)
+			}
+
+			day1 := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+			day2 := day1.Add(24 * time.Hour)
+			stop1, stop2 := day1.Add(time.Hour), day2.Add(time.Hour)
+			fake := fakeToggl{
+				entries: []domain.TimeEntry{
+					{ID: 1, Description: "first, with \"quotes\"", Tags: []string{"dev"}, Start: day1, Stop: &stop1, DurationSec: 3600},
+					{ID: 2, Description: "second", Start: day1.Add(2 * time.Hour), Stop: &stop1, DurationSec: 3600},
+					{ID: 3, Description: "next day", Start: day2, Stop: &stop2, DurationSec: 3600},
+				},
+				projects: []domain.Project{{ID: 5, WorkspaceID: 1, Name: "P", Active: true, At: day1}},
+			}
+			uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+			from, to := day1.Add(-time.Hour), day2.Add(12*time.Hour)
+			run, err := uc.Run(ctx, domain.TriggerCLI, from, to)
+			if err != nil {
+				t.Fatalf("sync run: %v", err)
+			}
+			if got := run.Counts["entries"].Inserted; got != 3 {
+				t.Fatalf("expected 3 inserted entries, got %d", got)
+			}
+			ext := "." + string(format)
+			for _, name := range []string{"2025-08-01", "2025-08-02"} {
+				if _, err := os.Stat(filepath.Join(dir, "entries", name+ext)); err != nil {
+					t.Fatalf("expected partition %s: %v", name, err)
+				}
+			}
+
+			// Re-syncing rewrites partitions instead of appending.
+			run, err = uc.Run(ctx, domain.TriggerCLI, from, to)
+			if err != nil {
+				t.Fatalf("sync run 2: %v", err)
+			}
+			if run.Changed() {
+				t.Fatalf("expected idempotent re-sync, got %+v", run.Counts)
+			}
+			if n := countRecords(t, filepath.Join(dir, "entries", "2025-08-01"+ext), format); n != 2 {
+				t.Fatalf("expected 2 records on 2025-08-01, got %d", n)
+			}
+
+			// Moving entry 2 to the next day and deleting entry 3 in Toggl
+			// leaves one record per day.
+			fake.entries[1].Start = day2.Add(2 * time.Hour)
+			fake.entries = fake.entries[:2]
+			uc.Toggl = fake
+			run, err = uc.Run(ctx, domain.TriggerCLI, from, to)
+			if err != nil {
+				t.Fatalf("sync run 3: %v", err)
+			}
+			if c := run.Counts["entries"]; c.Updated != 1 || c.Deleted != 1 {
+				t.Fatalf("expected 1 updated and 1 deleted entry, got %+v", c)
+			}
+			if n := countRecords(t, filepath.Join(dir, "entries", "2025-08-01"+ext), format); n != 1 {
+				t.Fatalf("expected 1 record on 2025-08-01, got %d", n)
+			}
+			if n := countRecords(t, filepath.Join(dir, "entries", "2025-08-02"+ext), format); n != 2 {
+				t.Fatalf("expected entry 2 and deleted entry 3 on 2025-08-02, got %d", n)
+			}
+		})
+	}
+}
+
+func TestExport_FromDatabase(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "toggl.db")
+	var cfg config.Config
+	cfg.Sink.Targets = []config.SinkTarget{{Name: "sqlite", Driver: config.SinkSQLite, DSN: path}}
+
+	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	projectID := int64(5)
+	sink := openSQLiteStore(t, ctx, path, logger)
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fakeToggl{entries: []domain.TimeEntry{
+		{ID: 2, Description: "later", Start: start.Add(time.Hour), Stop: &stop, DurationSec: 1800},
+		{ID: 1, Description: "Dev, work", ProjectID: &projectID, Tags: []string{"dev"}, Start: start, Stop: &stop, DurationSec: 3600, Billable: true},
+		// Toggl reports a running timer with duration -start and no stop.
+		{ID: 3, Description: "running", Start: start.Add(2 * time.Hour), DurationSec: -start.Add(2 * time.Hour).Unix()},
+	}}, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, start.Add(-time.Hour), start.Add(4*time.Hour)); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+
+	var buf bytes.Buffer
+	opts := app.ExportOptions{Source: app.ExportFromDB, Format: file.CSV, From: start.Add(-time.Hour), To: start.Add(4 * time.Hour)}
+	if err := app.Export(ctx, logger, cfg, opts, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := `id,workspace_id,project_id,task_id,user_id,description,start,stop,duration_sec,billable,tags,at,created_with,deleted_at,is_running
+1,,5,,,"Dev, work",2025-08-01T09:00:00Z,2025-08-01T10:00:00Z,3600,true,"[""dev""]",,,,false
+2,,,,,later,2025-08-01T10:00:00Z,2025-08-01T10:00:00Z,1800,false,[],,,,false
+`
+	got, running, _ := strings.Cut(buf.String(), "3,,,,,running,2025-08-01T11:00:00Z,,")
+	if got != want {
+		t.Fatalf("unexpected export:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	// The running timer exports with its provisional duration, flagged.
+	if !strings.HasSuffix(running, ",false,[],,,,true\n") {
+		t.Fatalf("expected the running timer to be flagged, got %q", running)
+	}
+}
+
+func TestExport_FromTogglFlagsRunningTimers(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	start := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	api := &fakeTogglAPI{entries: []togglEntry{
+		{ID: 1, Description: "done", Start: start.Add(-time.Hour), Duration: 1800, At: start},
+		// Toggl reports a running timer with duration -start and no stop.
+		{ID: 2, Description: "running", Start: start, Duration: -start.Unix(), At: start},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	var cfg config.Config
+	cfg.Toggl.APIToken = "token"
+	cfg.Toggl.BaseURL = srv.URL
+	cfg.Toggl.MaxAttempts = 1
+	cfg.Toggl.EntriesSource = config.EntriesSourceMe
+
+	var buf bytes.Buffer
+	opts := app.ExportOptions{Source: app.ExportFromToggl, Format: file.NDJSON, From: start.Add(-2 * time.Hour), To: start.Add(time.Hour)}
+	if err := app.Export(ctx, logger, cfg, opts, &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	dec := json.NewDecoder(&buf)
+	running := make(map[int64]bool)
+	for {
+		var rec struct {
+			ID          int64      `json:"id"`
+			Stop        *time.Time `json:"stop"`
+			DurationSec int64      `json:"duration_sec"`
+			IsRunning   bool       `json:"is_running"`
+		}
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		running[rec.ID] = rec.IsRunning
+		if rec.ID == 2 && (rec.Stop != nil || rec.DurationSec < 2*3600 || rec.DurationSec > 2*3600+300) {
+			t.Fatalf("expected a provisional 2h duration without stop, got %+v", rec)
+		}
+	}
+	if len(running) != 2 || running[1] || !running[2] {
+		t.Fatalf("expected only entry 2 to be flagged running, got %v", running)
+	}
+}
+
+func TestExport_FromDatabaseDoesNotMigrate(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "toggl.db")
+	var cfg config.Config
+	cfg.Sink.Targets = []config.SinkTarget{{Name: "sqlite", Driver: config.SinkSQLite, DSN: path}}
+	opts := app.ExportOptions{Source: app.ExportFromDB, Format: file.CSV, To: time.Now()}
+
+	// A missing database is reported, not created.
+	if err := app.Export(ctx, logger, cfg, opts, io.Discard); err == nil {
+		t.Fatal("expected an error for a missing database")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected export not to create %s, got %v", path, err)
+	}
+
+	// A database missing the latest migration is left alone.
+	if err := migrate.Run(ctx, migrate.SQLite, path, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations)"); err != nil {
+		t.Fatalf("drop migration: %v", err)
+	}
+	var before int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&before); err != nil {
+		t.Fatalf("count migrations: %v", err)
+	}
+	err = app.Export(ctx, logger, cfg, opts, io.Discard)
+	if !errors.Is(err, migrate.ErrSchemaBehind) {
+		t.Fatalf("expected ErrSchemaBehind, got %v", err)
+	}
+	var after int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&after); err != nil {
+		t.Fatalf("count migrations: %v", err)
+	}
+	if after != before {
+		t.Fatalf("expected export not to migrate, got %d applied migrations instead of %d", after, before)
+	}
+}
+
+func countRecords(t *testing.T, path string, format file.Format) int {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	n := strings.Count(string(b), "\n")
+	if format == file.CSV {
+		n-- // header
+	}
+	return n
+}
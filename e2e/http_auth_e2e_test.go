@@ -0,0 +1,152 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"toggl-scraper/internal/app"
+	"toggl-scraper/internal/config"
+)
+
+func TestHTTPAuth_RejectsBadTokens(t *testing.T) {
+	srv := newTestServer(t, "http://toggl.invalid")
+
+	for _, tc := range []struct {
+		name       string
+		method     string
+		path       string
+		auth       string
+		wantStatus int
+		wantKind   string
+	}{
+		{name: "missing", method: http.MethodPost, path: "/sync", wantStatus: http.StatusUnauthorized, wantKind: "unauthorized"},
+		{name: "basic scheme", method: http.MethodPost, path: "/sync", auth: "Basic " + testSyncToken, wantStatus: http.StatusUnauthorized, wantKind: "unauthorized"},
+		{name: "empty bearer", method: http.MethodGet, path: "/jobs/x", auth: "Bearer ", wantStatus: http.StatusUnauthorized, wantKind: "unauthorized"},
+		{name: "no scheme", method: http.MethodGet, path: "/healthz", auth: testReadToken, wantStatus: http.StatusUnauthorized, wantKind: "unauthorized"},
+		{name: "unknown token", method: http.MethodGet, path: "/healthz", auth: "Bearer nope", wantStatus: http.StatusUnauthorized, wantKind: "unauthorized"},
+		{name: "read token on sync", method: http.MethodPost, path: "/sync", auth: "Bearer " + testReadToken, wantStatus: http.StatusForbidden, wantKind: "forbidden"},
+		{name: "read token on post jobs", method: http.MethodPost, path: "/jobs", auth: "Bearer " + testReadToken, wantStatus: http.StatusForbidden, wantKind: "forbidden"},
+		{name: "read token on delete job", method: http.MethodDelete, path: "/jobs/x", auth: "Bearer " + testReadToken, wantStatus: http.StatusForbidden, wantKind: "forbidden"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, body := doJSONAs(t, srv, tc.method, tc.path, tc.auth)
+			if resp.StatusCode != tc.wantStatus || body["kind"] != tc.wantKind {
+				t.Fatalf("expected %d %s, got %d %v", tc.wantStatus, tc.wantKind, resp.StatusCode, body)
+			}
+			if got := resp.Header.Get("WWW-Authenticate"); (got != "") != (tc.wantStatus == http.StatusUnauthorized) {
+				t.Fatalf("unexpected WWW-Authenticate %q for %d", got, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPAuth_ScopesTokens(t *testing.T) {
+	srv := newTestServer(t, "http://toggl.invalid")
+
+	for _, tok := range []string{testReadToken, testSyncToken} {
+		resp, body := doRequest(t, srv, http.MethodGet, "/healthz", "bearer "+tok)
+		if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+			t.Fatalf("/healthz with %s: expected 200 ok, got %d %q", tok, resp.StatusCode, body)
+		}
+	}
+	// A read token may poll jobs; this one does not exist.
+	if resp, body := doJSONAs(t, srv, http.MethodGet, "/jobs/missing", "Bearer "+testReadToken); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for a read token polling an unknown job, got %d %v", resp.StatusCode, body)
+	}
+}
+
+func TestHTTPAuth_FailsClosedWithoutTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testConfig(t, "http://toggl.invalid")
+	cfg.HTTP.Tokens = nil
+	a, err := app.New(logger, cfg)
+	if err != nil {
+		t.Fatalf("app: %v", err)
+	}
+	if _, err := a.HTTPServer(""); !errors.Is(err, app.ErrNoHTTPAuth) {
+		t.Fatalf("expected ErrNoHTTPAuth, got %v", err)
+	}
+
+	// HTTP_AUTH=none explicitly opens the server.
+	cfg.HTTP.NoAuth = true
+	srv := startTestServer(t, cfg)
+	if resp, body := doRequest(t, srv, http.MethodGet, "/healthz", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected an open /healthz, got %d %q", resp.StatusCode, body)
+	}
+}
+
+// setHTTPEnv sets the environment config.Load reads for the HTTP server,
+// with a Toggl token so that Load succeeds.
+func setHTTPEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	t.Setenv("TOGGL_API_TOKEN", "token")
+	for _, name := range []string{"HTTP_SYNC_TOKENS", "HTTP_READ_TOKENS", "HTTP_TOKENS_FILE", "HTTP_AUTH"} {
+		t.Setenv(name, env[name])
+	}
+}
+
+func TestConfig_LoadsHTTPTokens(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	tokensFile := writeFile("tokens", "# deploy tokens\n\nsync  s3\n  read r2  \n")
+
+	setHTTPEnv(t, map[string]string{
+		"HTTP_SYNC_TOKENS": "s1 s2",
+		"HTTP_READ_TOKENS": "r1",
+		"HTTP_TOKENS_FILE": tokensFile,
+	})
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := []config.HTTPToken{
+		{Token: "s1", Scope: config.ScopeSync},
+		{Token: "s2", Scope: config.ScopeSync},
+		{Token: "r1", Scope: config.ScopeRead},
+		{Token: "s3", Scope: config.ScopeSync},
+		{Token: "r2", Scope: config.ScopeRead},
+	}
+	if !reflect.DeepEqual(cfg.HTTP.Tokens, want) || cfg.HTTP.NoAuth {
+		t.Fatalf("expected tokens %v with auth, got %v noAuth=%v", want, cfg.HTTP.Tokens, cfg.HTTP.NoAuth)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown scope", env: map[string]string{"HTTP_TOKENS_FILE": writeFile("scope", "read r1\nadmin a1\n")}, wantErr: "line 2"},
+		{name: "missing token", env: map[string]string{"HTTP_TOKENS_FILE": writeFile("short", "sync\n")}, wantErr: "line 1"},
+		{name: "extra field", env: map[string]string{"HTTP_TOKENS_FILE": writeFile("long", "sync s1 s2\n")}, wantErr: "line 1"},
+		{name: "missing file", env: map[string]string{"HTTP_TOKENS_FILE": filepath.Join(dir, "missing")}, wantErr: "HTTP_TOKENS_FILE"},
+		{name: "auth none with tokens", env: map[string]string{"HTTP_AUTH": "none", "HTTP_SYNC_TOKENS": "s1"}, wantErr: "HTTP_AUTH=none"},
+		{name: "unknown auth", env: map[string]string{"HTTP_AUTH": "off"}, wantErr: "HTTP_AUTH"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setHTTPEnv(t, tc.env)
+			if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected an error mentioning %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	setHTTPEnv(t, map[string]string{"HTTP_AUTH": "none"})
+	if cfg, err := config.Load(); err != nil || !cfg.HTTP.NoAuth || len(cfg.HTTP.Tokens) != 0 {
+		t.Fatalf("expected HTTP_AUTH=none to disable auth, got %+v, %v", cfg.HTTP, err)
+	}
+}
@@ -0,0 +1,340 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"toggl-scraper/internal/app"
+	"toggl-scraper/internal/config"
+)
+
+// Bearer tokens accepted by servers from newTestServer.
+const (
+	testSyncToken = "sync-token"
+	testReadToken = "read-token"
+)
+
+// testConfig configures an app that syncs from the Toggl API at togglURL
+// into a fresh SQLite database, with testSyncToken and testReadToken.
+func testConfig(t *testing.T, togglURL string) config.Config {
+	t.Helper()
+	var cfg config.Config
+	cfg.Toggl.APIToken = "token"
+	cfg.Toggl.BaseURL = togglURL
+	cfg.Toggl.MaxAttempts = 1
+	cfg.Toggl.EntriesSource = config.EntriesSourceMe
+	cfg.Sink.Targets = []config.SinkTarget{{Name: "sqlite", Driver: config.SinkSQLite, DSN: filepath.Join(t.TempDir(), "toggl.db")}}
+	cfg.Sink.BatchSize = 500
+	cfg.Sync.Timezone = "UTC"
+	cfg.HTTP.Tokens = []config.HTTPToken{
+		{Token: testSyncToken, Scope: config.ScopeSync},
+		{Token: testReadToken, Scope: config.ScopeRead},
+	}
+	return cfg
+}
+
+// newTestServer starts the HTTP trigger server of an app configured by
+// testConfig.
+func newTestServer(t *testing.T, togglURL string) *httptest.Server {
+	t.Helper()
+	return startTestServer(t, testConfig(t, togglURL))
+}
+
+// startTestServer starts the HTTP trigger server of an app for cfg.
+func startTestServer(t *testing.T, cfg config.Config) *httptest.Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a, err := app.New(logger, cfg)
+	if err != nil {
+		t.Fatalf("app: %v", err)
+	}
+	httpSrv, err := a.HTTPServer("")
+	if err != nil {
+		t.Fatalf("http server: %v", err)
+	}
+	srv := httptest.NewServer(httpSrv.Handler)
+	t.Cleanup(func() {
+		srv.Close()
+		_ = httpSrv.Shutdown(context.Background())
+	})
+	return srv
+}
+
+// doRequest sends a request to srv with the given Authorization header,
+// if any, and returns the response with its body.
+func doRequest(t *testing.T, srv *httptest.Server, method, path, auth string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: read: %v", method, path, err)
+	}
+	return resp, body
+}
+
+// doJSON sends a request to srv with testSyncToken and decodes the JSON
+// response.
+func doJSON(t *testing.T, srv *httptest.Server, method, path string) (*http.Response, map[string]any) {
+	t.Helper()
+	return doJSONAs(t, srv, method, path, "Bearer "+testSyncToken)
+}
+
+// doJSONAs is doJSON with the given Authorization header.
+func doJSONAs(t *testing.T, srv *httptest.Server, method, path, auth string) (*http.Response, map[string]any) {
+	t.Helper()
+	resp, raw := doRequest(t, srv, method, path, auth)
+	var body map[string]any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, path, raw, err)
+	}
+	return resp, body
+}
+
+func TestHTTPSync_MapsTogglErrors(t *testing.T) {
+	// A closed server refuses connections.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	for _, tc := range []struct {
+		name       string
+		status     int // Toggl response; 0 uses the closed server
+		body       string
+		wantStatus int
+		wantKind   string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantStatus: http.StatusBadGateway, wantKind: "toggl_auth"},
+		{name: "forbidden", status: http.StatusForbidden, wantStatus: http.StatusBadGateway, wantKind: "toggl_forbidden"},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantStatus: http.StatusTooManyRequests, wantKind: "toggl_rate_limited"},
+		{name: "server error", status: http.StatusServiceUnavailable, wantStatus: http.StatusBadGateway, wantKind: "toggl_unavailable"},
+		{name: "bad request", status: http.StatusBadRequest, wantStatus: http.StatusBadGateway, wantKind: "toggl_error"},
+		{name: "bad body", status: http.StatusOK, body: "{", wantStatus: http.StatusBadGateway, wantKind: "toggl_decode"},
+		{name: "unreachable", wantStatus: http.StatusBadGateway, wantKind: "toggl_unreachable"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			togglURL := closed.URL
+			if tc.status != 0 {
+				toggl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Retry-After", "7")
+					w.WriteHeader(tc.status)
+					_, _ = io.WriteString(w, tc.body)
+				}))
+				defer toggl.Close()
+				togglURL = toggl.URL
+			}
+			srv := newTestServer(t, togglURL)
+
+			resp, body := doJSON(t, srv, http.MethodPost, "/sync?from=2025-08-01&to=2025-08-02")
+			if resp.StatusCode != tc.wantStatus || body["kind"] != tc.wantKind {
+				t.Fatalf("expected %d %s, got %d %v", tc.wantStatus, tc.wantKind, resp.StatusCode, body)
+			}
+			if tc.status == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "7" {
+				t.Fatalf("expected Retry-After to be forwarded, got %q", resp.Header.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+// blockingToggl serves an empty Toggl account whose time entry listings
+// block until released or cancelled. Each blocked listing is announced on
+// started.
+type blockingToggl struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingToggl(t *testing.T) (*blockingToggl, *httptest.Server) {
+	t.Helper()
+	b := &blockingToggl{started: make(chan struct{}, 16), release: make(chan struct{})}
+	srv := httptest.NewServer(b)
+	// Registered before the app's cleanup, so it runs after the app has
+	// cancelled its jobs and no listing is still blocked.
+	t.Cleanup(srv.Close)
+	return b, srv
+}
+
+func (b *blockingToggl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/api/v9/me":
+		_, _ = io.WriteString(w, `{"id":1}`)
+		return
+	case strings.HasSuffix(r.URL.Path, "/time_entries"):
+		b.started <- struct{}{}
+		select {
+		case <-b.release:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	_, _ = io.WriteString(w, "[]")
+}
+
+// waitStarted waits until a sync is blocked listing time entries.
+func (b *blockingToggl) waitStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case <-b.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the sync to list time entries")
+	}
+}
+
+// postJob queues a job and returns its ID.
+func postJob(t *testing.T, srv *httptest.Server, query string) string {
+	t.Helper()
+	resp, body := doJSON(t, srv, http.MethodPost, "/jobs"+query)
+	if resp.StatusCode != http.StatusAccepted || body["state"] != "queued" {
+		t.Fatalf("expected 202 queued, got %d %v", resp.StatusCode, body)
+	}
+	id, _ := body["id"].(string)
+	if loc := resp.Header.Get("Location"); loc != "/jobs/"+id {
+		t.Fatalf("expected Location /jobs/%s, got %q", id, loc)
+	}
+	return id
+}
+
+// waitJob polls GET /jobs/{id} until the job reaches state.
+func waitJob(t *testing.T, srv *httptest.Server, id, state string) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, body := doJSON(t, srv, http.MethodGet, "/jobs/"+id)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /jobs/%s: expected 200, got %d %v", id, resp.StatusCode, body)
+		}
+		if body["state"] == state {
+			return body
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s: expected state %s, got %v", id, state, body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHTTPJobs_RunsQueuedJob(t *testing.T) {
+	toggl, togglSrv := newBlockingToggl(t)
+	close(toggl.release)
+	srv := newTestServer(t, togglSrv.URL)
+
+	id := postJob(t, srv, "?from=2025-08-01&to=2025-08-02")
+	body := waitJob(t, srv, id, "ok")
+	if body["mode"] != "window" || body["run_id"] == nil || body["started_at"] == nil || body["finished_at"] == nil {
+		t.Fatalf("expected a finished window job with a run, got %v", body)
+	}
+
+	// Finished jobs cannot be cancelled; unknown ones are not found.
+	if resp, body := doJSON(t, srv, http.MethodDelete, "/jobs/"+id); resp.StatusCode != http.StatusConflict || body["state"] != "ok" {
+		t.Fatalf("expected 409 for a finished job, got %d %v", resp.StatusCode, body)
+	}
+	if resp, _ := doJSON(t, srv, http.MethodGet, "/jobs/missing"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown job, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPJobs_CancelsQueuedJob(t *testing.T) {
+	toggl, togglSrv := newBlockingToggl(t)
+	srv := newTestServer(t, togglSrv.URL)
+
+	first := postJob(t, srv, "")
+	toggl.waitStarted(t)
+	second := postJob(t, srv, "")
+
+	resp, body := doJSON(t, srv, http.MethodDelete, "/jobs/"+second)
+	if resp.StatusCode != http.StatusAccepted || body["state"] != "cancelled" {
+		t.Fatalf("expected a queued job to be cancelled at once, got %d %v", resp.StatusCode, body)
+	}
+	close(toggl.release)
+	waitJob(t, srv, first, "ok")
+	if body := waitJob(t, srv, second, "cancelled"); body["started_at"] != nil {
+		t.Fatalf("expected the cancelled job never to start, got %v", body)
+	}
+}
+
+func TestHTTPJobs_CancelsRunningJob(t *testing.T) {
+	toggl, togglSrv := newBlockingToggl(t)
+	srv := newTestServer(t, togglSrv.URL)
+
+	id := postJob(t, srv, "?from=2025-08-01&to=2025-08-02")
+	toggl.waitStarted(t)
+	resp, body := doJSON(t, srv, http.MethodDelete, "/jobs/"+id)
+	if resp.StatusCode != http.StatusAccepted || body["state"] != "running" {
+		t.Fatalf("expected 202 for a running job, got %d %v", resp.StatusCode, body)
+	}
+	if body := waitJob(t, srv, id, "cancelled"); body["finished_at"] == nil {
+		t.Fatalf("expected the cancelled job to be finished, got %v", body)
+	}
+}
+
+func TestHTTPJobs_TimesOut(t *testing.T) {
+	_, togglSrv := newBlockingToggl(t)
+	srv := newTestServer(t, togglSrv.URL)
+
+	id := postJob(t, srv, "?from=2025-08-01&to=2025-08-02&timeout=50ms")
+	body := waitJob(t, srv, id, "error")
+	if msg, _ := body["error"].(string); !strings.Contains(msg, context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected a deadline error, got %v", body)
+	}
+}
+
+func TestHTTPJobs_StoresQueuedJobsAtShutdown(t *testing.T) {
+	toggl, togglSrv := newBlockingToggl(t)
+	cfg := testConfig(t, togglSrv.URL)
+	a, err := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	if err != nil {
+		t.Fatalf("app: %v", err)
+	}
+	httpSrv, err := a.HTTPServer("")
+	if err != nil {
+		t.Fatalf("http server: %v", err)
+	}
+	srv := httptest.NewServer(httpSrv.Handler)
+	defer srv.Close()
+
+	first := postJob(t, srv, "")
+	toggl.waitStarted(t)
+	second := postJob(t, srv, "")
+	if err := httpSrv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	// A restarted server answers for both jobs once they are stored.
+	restarted := startTestServer(t, cfg)
+	for _, id := range []string{first, second} {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			resp, body := doJSON(t, restarted, http.MethodGet, "/jobs/"+id)
+			if resp.StatusCode == http.StatusOK && body["state"] == "cancelled" && body["finished_at"] != nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("job %s: expected a stored cancelled job, got %d %v", id, resp.StatusCode, body)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	_, body := doJSON(t, restarted, http.MethodGet, "/jobs/"+second)
+	if body["started_at"] != nil {
+		t.Fatalf("expected the queued job never to start, got %v", body)
+	}
+}
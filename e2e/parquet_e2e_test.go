@@ -0,0 +1,144 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+
+	"toggl-scraper/internal/adapter/parquet"
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/usecase"
+)
+
+// parquetEntry reads back the columns the test checks.
+type parquetEntry struct {
+	ID        int64      `parquet:"id"`
+	DeletedAt *time.Time `parquet:"deleted_at"`
+	IsRunning bool       `parquet:"is_running"`
+}
+
+func TestParquetSink_HivePartitions(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	sink, err := parquet.NewSink(dir, logger)
+	if err != nil {
+		t.Fatalf("parquet sink: %v", err)
+	}
+
+	day1 := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	stop1, stop2 := day1.Add(time.Hour), day2.Add(time.Hour)
+	projectID := int64(5)
+	fake := fakeToggl{
+		entries: []domain.TimeEntry{
+			{ID: 1, Description: "first", ProjectID: &projectID, Tags: []string{"dev"}, Start: day1, Stop: &stop1, DurationSec: 3600},
+			{ID: 2, Description: "second", Start: day1.Add(2 * time.Hour), Stop: &stop1, DurationSec: 3600},
+			{ID: 3, Description: "next day", Start: day2, Stop: &stop2, DurationSec: 3600},
+		},
+		projects: []domain.Project{{ID: 5, WorkspaceID: 1, Name: "P", Active: true, At: day1}},
+	}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+	from, to := day1.Add(-time.Hour), day2.Add(12*time.Hour)
+	run, err := uc.Run(ctx, domain.TriggerCLI, from, to)
+	if err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+	if got := run.Counts["entries"].Inserted; got != 3 {
+		t.Fatalf("expected 3 inserted entries, got %d", got)
+	}
+	part1 := filepath.Join(dir, "entries", "year=2025", "month=08", "day=01", "part-00000.parquet")
+	part2 := filepath.Join(dir, "entries", "year=2025", "month=08", "day=02", "part-00000.parquet")
+	if rows := readParquet(t, part1); len(rows) != 2 {
+		t.Fatalf("expected 2 rows on 2025-08-01, got %d", len(rows))
+	}
+
+	// Re-syncing rewrites the partition instead of appending duplicates.
+	run, err = uc.Run(ctx, domain.TriggerCLI, from, to)
+	if err != nil {
+		t.Fatalf("sync run 2: %v", err)
+	}
+	if run.Changed() {
+		t.Fatalf("expected idempotent re-sync, got %+v", run.Counts)
+	}
+	if rows := readParquet(t, part1); len(rows) != 2 {
+		t.Fatalf("expected 2 rows on 2025-08-01 after re-sync, got %d", len(rows))
+	}
+
+	// Moving entry 2 to the next day and deleting entry 3 in Toggl leaves
+	// one row on the first day and a soft-deleted row on the second. A
+	// timer started since is written flagged as running; Toggl reports it
+	// with duration -start and no stop.
+	fake.entries[1].Start = day2.Add(2 * time.Hour)
+	running := day2.Add(4 * time.Hour)
+	fake.entries = append(fake.entries[:2], domain.TimeEntry{ID: 4, Description: "running", Start: running, DurationSec: -running.Unix()})
+	uc.Toggl = fake
+	run, err = uc.Run(ctx, domain.TriggerCLI, from, to)
+	if err != nil {
+		t.Fatalf("sync run 3: %v", err)
+	}
+	if c := run.Counts["entries"]; c.Updated != 1 || c.Deleted != 1 {
+		t.Fatalf("expected 1 updated and 1 deleted entry, got %+v", c)
+	}
+	if rows := readParquet(t, part1); len(rows) != 1 || rows[0].ID != 1 {
+		t.Fatalf("expected only entry 1 on 2025-08-01, got %+v", rows)
+	}
+	rows := readParquet(t, part2)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows on 2025-08-02, got %d", len(rows))
+	}
+	for _, r := range rows {
+		if deleted := r.DeletedAt != nil; deleted != (r.ID == 3) {
+			t.Fatalf("entry %d: unexpected deleted_at %v", r.ID, r.DeletedAt)
+		}
+		if r.IsRunning != (r.ID == 4) {
+			t.Fatalf("entry %d: unexpected is_running %v", r.ID, r.IsRunning)
+		}
+	}
+
+	// No temporary files are left next to the partitions.
+	tmp, _ := filepath.Glob(filepath.Join(dir, "entries", "*", "*", "*", ".*"))
+	if len(tmp) != 0 {
+		t.Fatalf("unexpected temporary files: %v", tmp)
+	}
+}
+
+func readParquet(t *testing.T, path string) []parquetEntry {
+	t.Helper()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected partition %s: %v", path, err)
+	}
+	rows, err := pq.ReadFile[parquetEntry](path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return rows
+}
+
+func TestParquetSink_UntaggedEntryIsUnchanged(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sink, err := parquet.NewSink(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("parquet sink: %v", err)
+	}
+
+	// The Reports source builds an empty, non-nil tag slice.
+	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	entries := []domain.TimeEntry{{ID: 1, Description: "untagged", Tags: []string{}, Start: start, Stop: &stop, DurationSec: 3600}}
+	if c, err := sink.SyncEntries(ctx, entries); err != nil || c.Inserted != 1 {
+		t.Fatalf("expected 1 inserted entry, got %+v, %v", c, err)
+	}
+	if c, err := sink.SyncEntries(ctx, entries); err != nil || c.Unchanged != 1 || c.Updated != 0 {
+		t.Fatalf("expected the identical entry to be unchanged, got %+v, %v", c, err)
+	}
+}
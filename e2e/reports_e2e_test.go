@@ -0,0 +1,192 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tg "toggl-scraper/internal/adapter/toggl"
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/usecase"
+)
+
+// reportsSearch is the subset of a Reports API search body the fake reads.
+type reportsSearch struct {
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	PageSize       int    `json:"page_size"`
+	FirstID        int64  `json:"first_id"`
+	FirstRowNumber int64  `json:"first_row_number"`
+}
+
+// fakeReportsAPI serves the Reports API v3 detailed search from entries,
+// one row per entry, filtering by start date like Toggl and paginating
+// with X-Next-ID / X-Next-Row-Number. Every other path returns no
+// reference data.
+type fakeReportsAPI struct {
+	mu       sync.Mutex
+	entries  []togglEntry
+	searches []reportsSearch
+}
+
+func (f *fakeReportsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/tasks"):
+		_, _ = io.WriteString(w, `{"data":[],"total_count":0}`)
+		return
+	case !strings.HasPrefix(r.URL.Path, "/reports/"):
+		_, _ = io.WriteString(w, "[]")
+		return
+	}
+	var req reportsSearch
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, _ := time.Parse(time.DateOnly, req.StartDate)
+	to, _ := time.Parse(time.DateOnly, req.EndDate)
+	to = to.Add(24 * time.Hour) // end_date is inclusive
+
+	f.mu.Lock()
+	f.searches = append(f.searches, req)
+	var matched []togglEntry
+	for _, e := range f.entries {
+		if !e.Start.Before(from) && e.Start.Before(to) {
+			matched = append(matched, e)
+		}
+	}
+	f.mu.Unlock()
+
+	first := max(int(req.FirstRowNumber)-1, 0)
+	last := min(first+req.PageSize, len(matched))
+	type row struct {
+		UserID      int64  `json:"user_id"`
+		Description string `json:"description"`
+		TimeEntries []any  `json:"time_entries"`
+	}
+	rows := []row{}
+	for _, e := range matched[min(first, last):last] {
+		rows = append(rows, row{UserID: 1, Description: e.Description, TimeEntries: []any{map[string]any{
+			"id": e.ID, "seconds": 60, "start": e.Start, "stop": e.Start.Add(time.Minute), "at": e.At,
+		}}})
+	}
+	if last < len(matched) {
+		w.Header().Set("X-Next-ID", strconv.FormatInt(matched[last].ID, 10))
+		w.Header().Set("X-Next-Row-Number", strconv.Itoa(last+1))
+	}
+	_ = json.NewEncoder(w).Encode(rows)
+}
+
+// newTestReportsClient returns a reports client for srv in workspace 1.
+func newTestReportsClient(t *testing.T, srv *httptest.Server) *tg.ReportsClient {
+	t.Helper()
+	client, err := tg.NewReportsClient(newTestTogglClient(srv, 1))
+	if err != nil {
+		t.Fatalf("reports client: %v", err)
+	}
+	return client
+}
+
+func TestReportsClient_FollowsPagination(t *testing.T) {
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	// 120 entries take three pages of 50.
+	api := &fakeReportsAPI{entries: spreadEntries(1, 120, from, 24*time.Hour)}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	entries, err := newTestReportsClient(t, srv).ListTimeEntries(context.Background(), from, from.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	assertEntryIDs(t, entries, 120)
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.searches) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(api.searches))
+	}
+	if s := api.searches[0]; s.FirstID != 0 || s.FirstRowNumber != 0 {
+		t.Fatalf("expected the first page to start at the beginning, got %+v", s)
+	}
+	for i, want := range []int64{51, 101} {
+		if s := api.searches[i+1]; s.FirstID != want || s.FirstRowNumber != want {
+			t.Fatalf("page %d: expected to continue at row %d, got %+v", i+2, want, s)
+		}
+	}
+}
+
+func TestReportsIncremental_RefetchesLookbackWindow(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now().UTC().Truncate(time.Second)
+	day := 24 * time.Hour
+	api := &fakeReportsAPI{entries: []togglEntry{
+		{ID: 1, Description: "old", Start: now.Add(-3 * day), At: now.Add(-3 * day)},
+		{ID: 2, Description: "doomed", Start: now.Add(-2 * day), At: now.Add(-2 * day)},
+		{ID: 3, Description: "ancient", Start: now.Add(-20 * day), At: now.Add(-20 * day)},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	sink := openSQLiteStore(t, ctx, filepath.Join(t.TempDir(), "toggl.db"), logger)
+	uc := &usecase.SyncUseCase{
+		Log:         logger,
+		Toggl:       newTestReportsClient(t, srv),
+		Sink:        sink,
+		Watermarks:  sink,
+		Running:     sink,
+		WorkspaceID: 1,
+		Lookback:    7 * day,
+	}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, now.Add(-30*day), now); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+	mark := now.Add(-time.Hour)
+	if err := sink.SaveWatermark(ctx, 1, mark); err != nil {
+		t.Fatalf("save watermark: %v", err)
+	}
+
+	// Entry 1 started before the watermark is edited and entry 2 deleted;
+	// the Reports API only filters by start, so neither shows up as a
+	// change since the watermark.
+	api.mu.Lock()
+	api.entries = []togglEntry{
+		{ID: 1, Description: "edited", Start: now.Add(-3 * day), At: now.Add(-time.Minute)},
+		api.entries[2],
+	}
+	api.mu.Unlock()
+
+	run, err := uc.RunIncremental(ctx, domain.TriggerCLI, now)
+	if err != nil {
+		t.Fatalf("incremental sync: %v", err)
+	}
+	if got := run.Counts["entries"]; got.Updated != 1 || got.Deleted != 1 {
+		t.Fatalf("expected 1 updated and 1 deleted entry, got %+v", got)
+	}
+	stored, err := sink.ListEntries(ctx, now.Add(-30*day), now)
+	if err != nil {
+		t.Fatalf("list entries: %v", err)
+	}
+	got := make(map[int64]string)
+	for _, e := range stored {
+		got[e.ID] = e.Description
+	}
+	if len(got) != 2 || got[1] != "edited" || got[3] != "ancient" {
+		t.Fatalf("expected entry 1 edited, 2 deleted and 3 kept, got %v", got)
+	}
+	if saved, _, err := sink.LoadWatermark(ctx, 1); err != nil || !saved.Equal(mark) {
+		t.Fatalf("expected the watermark to stay at %v, got %v (%v)", mark, saved, err)
+	}
+}
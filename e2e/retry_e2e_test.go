@@ -0,0 +1,241 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tg "toggl-scraper/internal/adapter/toggl"
+)
+
+// scriptedResponse is one response served by scriptedServer.
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// scriptedServer serves its responses in order, repeating the last one,
+// and records the request bodies it received.
+type scriptedServer struct {
+	responses []scriptedResponse
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (s *scriptedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	n := len(s.bodies)
+	s.bodies = append(s.bodies, string(body))
+	s.mu.Unlock()
+
+	resp := s.responses[min(n, len(s.responses)-1)]
+	if resp.retryAfter != "" {
+		w.Header().Set("Retry-After", resp.retryAfter)
+	}
+	if resp.body == "" {
+		resp.body = "[]"
+	}
+	w.WriteHeader(resp.status)
+	_, _ = io.WriteString(w, resp.body)
+}
+
+func (s *scriptedServer) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+// retryClient returns a client for srv with the given retry policy.
+func retryClient(srv *httptest.Server, policy tg.RetryPolicy) *tg.Client {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return tg.NewClient(srv.URL, "token", 0, policy, logger)
+}
+
+func TestRetry_RetriesTransientStatusesWithBackoff(t *testing.T) {
+	api := &scriptedServer{responses: []scriptedResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusBadGateway},
+		{status: http.StatusOK},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	policy := tg.RetryPolicy{MaxAttempts: 5, BaseDelay: 20 * time.Millisecond, MaxDelay: time.Second}
+	start := time.Now()
+	if _, err := retryClient(srv, policy).ListProjects(context.Background()); err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	elapsed := time.Since(start)
+	if n := api.calls(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	// Jittered backoff waits between half and all of 20ms, then 40ms.
+	if elapsed < 30*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("expected at least 30ms of jittered backoff, took %v", elapsed)
+	}
+}
+
+func TestRetry_StopsAtMaxAttempts(t *testing.T) {
+	api := &scriptedServer{responses: []scriptedResponse{{status: http.StatusServiceUnavailable}}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	policy := tg.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	_, err := retryClient(srv, policy).ListProjects(context.Background())
+	var serverErr *tg.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("expected *ServerError, got %v", err)
+	}
+	if n := api.calls(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetry_DoesNotRetryOtherStatuses(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			api := &scriptedServer{responses: []scriptedResponse{{status: status}, {status: http.StatusOK}}}
+			srv := httptest.NewServer(api)
+			defer srv.Close()
+
+			policy := tg.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+			if _, err := retryClient(srv, policy).ListProjects(context.Background()); err == nil {
+				t.Fatalf("expected an error for status %d", status)
+			}
+			if n := api.calls(); n != 1 {
+				t.Fatalf("expected 1 attempt, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRetry_CapsRetryAfterAtMaxDelay(t *testing.T) {
+	api := &scriptedServer{responses: []scriptedResponse{
+		{status: http.StatusTooManyRequests, retryAfter: "86400"},
+		{status: http.StatusOK},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	policy := tg.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	start := time.Now()
+	if _, err := retryClient(srv, policy).ListProjects(context.Background()); err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 5*time.Second {
+		t.Fatalf("expected to wait the 50ms max delay, took %v", elapsed)
+	}
+}
+
+func TestRetry_WaitsRetryAfterSeconds(t *testing.T) {
+	api := &scriptedServer{responses: []scriptedResponse{
+		{status: http.StatusTooManyRequests, retryAfter: "1"},
+		{status: http.StatusOK},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	policy := tg.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Second}
+	start := time.Now()
+	if _, err := retryClient(srv, policy).ListProjects(context.Background()); err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 5*time.Second {
+		t.Fatalf("expected to wait the 1s Retry-After, took %v", elapsed)
+	}
+}
+
+func TestRetry_FailsFastWhenRetryAfterExceedsDeadline(t *testing.T) {
+	for name, retryAfter := range map[string]string{
+		"seconds":   "3600",
+		"http-date": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &scriptedServer{responses: []scriptedResponse{
+				{status: http.StatusTooManyRequests, retryAfter: retryAfter},
+				{status: http.StatusOK},
+			}}
+			srv := httptest.NewServer(api)
+			defer srv.Close()
+
+			policy := tg.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Hour}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			start := time.Now()
+			_, err := retryClient(srv, policy).ListProjects(ctx)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("expected to fail fast, took %v", elapsed)
+			}
+			var rateErr *tg.RateLimitError
+			if !errors.As(err, &rateErr) {
+				t.Fatalf("expected *RateLimitError, got %v", err)
+			}
+			if rateErr.RetryAfter < 59*time.Minute || rateErr.RetryAfter > time.Hour {
+				t.Fatalf("expected Retry-After of about an hour, got %v", rateErr.RetryAfter)
+			}
+			if n := api.calls(); n != 1 {
+				t.Fatalf("expected 1 attempt, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRetry_PastRetryAfterDateRetriesRightAway(t *testing.T) {
+	api := &scriptedServer{responses: []scriptedResponse{
+		{status: http.StatusServiceUnavailable, retryAfter: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+		{status: http.StatusOK},
+	}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	policy := tg.RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: 10 * time.Second}
+	start := time.Now()
+	if _, err := retryClient(srv, policy).ListProjects(context.Background()); err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected an immediate retry, took %v", elapsed)
+	}
+}
+
+func TestRetry_ReplaysRequestBody(t *testing.T) {
+	api := &scriptedServer{responses: []scriptedResponse{
+		{status: http.StatusBadGateway},
+		{status: http.StatusOK},
+	}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v9/workspaces/1/tags", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "[]")
+	})
+	mux.Handle("/reports/", api)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	policy := tg.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	client, err := tg.NewReportsClient(tg.NewClient(srv.URL, "token", 1, policy, logger))
+	if err != nil {
+		t.Fatalf("reports client: %v", err)
+	}
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := client.ListTimeEntries(context.Background(), from, from.Add(24*time.Hour)); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.bodies) != 2 || api.bodies[0] == "" || api.bodies[0] != api.bodies[1] {
+		t.Fatalf("expected the same body on both attempts, got %q", api.bodies)
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +18,8 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	msql "toggl-scraper/internal/adapter/mysql"
+	pg "toggl-scraper/internal/adapter/postgres"
+	"toggl-scraper/internal/adapter/sqlite"
 	"toggl-scraper/internal/domain"
 	"toggl-scraper/internal/migrate"
 	"toggl-scraper/internal/ports"
@@ -24,90 +29,104 @@ import (
 type fakeToggl struct {
 	entries  []domain.TimeEntry
 	projects []domain.Project
+	clients  []domain.Client
+	tags     []domain.Tag
+	tasks    []domain.Task
+	users    []domain.User
+	groups   []domain.Group
 }
 
 func (f fakeToggl) ListTimeEntries(ctx context.Context, from, to time.Time) ([]domain.TimeEntry, error) {
-	return f.entries, nil
+	var out []domain.TimeEntry
+	for _, e := range f.entries {
+		if !e.Start.Before(from) && e.Start.Before(to) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeToggl) ListTimeEntriesSince(ctx context.Context, since time.Time) ([]domain.TimeEntry, error) {
+	var out []domain.TimeEntry
+	for _, e := range f.entries {
+		if !e.At.Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
 }
 
 func (f fakeToggl) ListProjects(ctx context.Context) ([]domain.Project, error) {
 	return f.projects, nil
 }
 
-func TestSyncToMySQL_UpsertsEntries(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping in short mode")
-	}
-	ctx := context.Background()
+func (f fakeToggl) ListClients(ctx context.Context) ([]domain.Client, error) {
+	return f.clients, nil
+}
 
-	// Start MySQL container
-	req := testcontainers.ContainerRequest{
-		Image:        "mysql:8.0",
-		ExposedPorts: []string{"3306/tcp"},
-		Env: map[string]string{
-			"MYSQL_DATABASE":      "testdb",
-			"MYSQL_ROOT_PASSWORD": "secret",
-			"MYSQL_USER":          "test",
-			"MYSQL_PASSWORD":      "pass",
-		},
-		WaitingFor: wait.ForListeningPort("3306/tcp").WithStartupTimeout(90 * time.Second),
-	}
-	mysqlC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
-	})
-	if err != nil {
-		t.Fatalf("failed to start mysql container: %v", err)
-	}
-	t.Cleanup(func() { _ = mysqlC.Terminate(context.Background()) })
+func (f fakeToggl) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	return f.tags, nil
+}
 
-	host, err := mysqlC.Host(ctx)
-	if err != nil {
-		t.Fatalf("host: %v", err)
-	}
-	port, err := mysqlC.MappedPort(ctx, "3306/tcp")
-	if err != nil {
-		t.Fatalf("mapped port: %v", err)
-	}
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true", "test", "pass", host, port.Port(), "testdb")
+func (f fakeToggl) ListTasks(ctx context.Context) ([]domain.Task, error) {
+	return f.tasks, nil
+}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-	if err := migrate.Run(ctx, dsn, logger); err != nil {
-		t.Fatalf("migrate: %v", err)
-	}
-	sink, err := msql.NewClient(ctx, dsn, logger)
-	if err != nil {
-		t.Fatalf("mysql client: %v", err)
-	}
-	t.Cleanup(func() { _ = sink.Close() })
+func (f fakeToggl) ListUsers(ctx context.Context) ([]domain.User, error) {
+	return f.users, nil
+}
+
+func (f fakeToggl) ListGroups(ctx context.Context) ([]domain.Group, error) {
+	return f.groups, nil
+}
+
+func TestSync_UpsertsEntries(t *testing.T) {
+	forEachBackend(t, testUpsertsEntries)
+}
 
+func testUpsertsEntries(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
 	// Prepare fake entries
 	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
 	stop := start.Add(90 * time.Minute)
 	projectID := int64(123)
 	workspaceID := int64(456)
+	clientID := int64(789)
+	taskID := int64(321)
+	estimate := int64(7200)
+	userID := int64(42)
 	fake := fakeToggl{
 		entries: []domain.TimeEntry{
-			{ID: 1, Description: "Dev work", ProjectID: &projectID, WorkspaceID: &workspaceID, Tags: []string{"dev", "feature"}, Start: start, Stop: &stop, DurationSec: 5400},
-			{ID: 2, Description: "Meeting", ProjectID: nil, WorkspaceID: &workspaceID, Tags: []string{"meeting"}, Start: start.Add(2 * time.Hour), Stop: &stop, DurationSec: 3600},
+			{ID: 1, Description: "Dev work", ProjectID: &projectID, TaskID: &taskID, WorkspaceID: &workspaceID, Tags: []string{"dev", "feature"}, TagIDs: []int64{11, 12}, Start: start, Stop: &stop, DurationSec: 5400, Billable: true, UserID: &userID, At: stop, CreatedWith: "e2e"},
+			{ID: 2, Description: "Meeting", ProjectID: nil, WorkspaceID: &workspaceID, Tags: []string{"meeting"}, TagIDs: []int64{13}, Start: start.Add(2 * time.Hour), Stop: &stop, DurationSec: 3600},
 		},
 		projects: []domain.Project{
-			{ID: projectID, WorkspaceID: workspaceID, Name: "Project X", Active: true, Private: false, Color: "#FFFFFF", ClientID: nil, At: time.Now().UTC()},
+			{ID: projectID, WorkspaceID: workspaceID, Name: "Project X", Active: true, Private: false, Color: "#FFFFFF", ClientID: &clientID, At: time.Now().UTC()},
+		},
+		clients: []domain.Client{
+			{ID: clientID, WorkspaceID: workspaceID, Name: "Acme", Archived: false, At: time.Now().UTC()},
+		},
+		tags: []domain.Tag{
+			{ID: 11, WorkspaceID: workspaceID, Name: "dev", At: time.Now().UTC()},
+			{ID: 12, WorkspaceID: workspaceID, Name: "feature", At: time.Now().UTC()},
+			{ID: 13, WorkspaceID: workspaceID, Name: "meeting", At: time.Now().UTC()},
+		},
+		tasks: []domain.Task{
+			{ID: taskID, WorkspaceID: workspaceID, ProjectID: projectID, Name: "Build API", EstimatedSeconds: &estimate, Active: true, At: time.Now().UTC()},
+		},
+		users: []domain.User{
+			{ID: userID, WorkspaceID: workspaceID, Name: "Ada", Email: "ada@example.com", Active: true, GroupIDs: []int64{7}},
+		},
+		groups: []domain.Group{
+			{ID: 7, WorkspaceID: workspaceID, Name: "Engineering"},
 		},
 	}
 
-	uc := &usecase.SyncUseCase{Log: logger, Toggl: ports.TogglClient(fake), Sink: sink}
-	if err := uc.Run(ctx, start.Add(-time.Hour), start.Add(4*time.Hour)); err != nil {
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: ports.TogglClient(fake), Sink: sink, Runs: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, start.Add(-time.Hour), start.Add(4*time.Hour)); err != nil {
 		t.Fatalf("sync run: %v", err)
 	}
 
 	// Verify rows
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		t.Fatalf("sql open: %v", err)
-	}
-	defer db.Close()
-
 	var count int
 	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entries").Scan(&count); err != nil {
 		t.Fatalf("count: %v", err)
@@ -123,10 +142,51 @@ func TestSyncToMySQL_UpsertsEntries(t *testing.T) {
 		t.Fatalf("expected 1 project row, got %d", count)
 	}
 
+	var clientName string
+	if err := db.QueryRowContext(ctx, "SELECT c.name FROM toggl_projects p JOIN toggl_clients c ON c.id = p.client_id WHERE p.id = 123").Scan(&clientName); err != nil {
+		t.Fatalf("client join: %v", err)
+	}
+	if clientName != "Acme" {
+		t.Fatalf("expected client name Acme, got %q", clientName)
+	}
+
+	var billable bool
+	var user int64
+	if err := db.QueryRowContext(ctx, "SELECT billable, user_id FROM toggl_time_entries WHERE id = 1").Scan(&billable, &user); err != nil {
+		t.Fatalf("entry metadata: %v", err)
+	}
+	if !billable || user != userID {
+		t.Fatalf("expected billable entry for user %d, got billable=%v user=%d", userID, billable, user)
+	}
+
+	var groupName string
+	if err := db.QueryRowContext(ctx, `SELECT g.name FROM toggl_time_entries e
+JOIN toggl_users u ON u.id = e.user_id
+JOIN toggl_group_members m ON m.user_id = u.id
+JOIN toggl_groups g ON g.id = m.group_id
+WHERE e.id = 1`).Scan(&groupName); err != nil {
+		t.Fatalf("user/group join: %v", err)
+	}
+	if groupName != "Engineering" {
+		t.Fatalf("expected group Engineering, got %q", groupName)
+	}
+
+	var tracked, estimated int64
+	if err := db.QueryRowContext(ctx, "SELECT SUM(e.duration_sec), MAX(t.estimated_seconds) FROM toggl_time_entries e JOIN toggl_tasks t ON t.id = e.task_id WHERE t.id = 321").Scan(&tracked, &estimated); err != nil {
+		t.Fatalf("task join: %v", err)
+	}
+	if tracked != 5400 || estimated != 7200 {
+		t.Fatalf("expected tracked=5400 estimated=7200, got %d/%d", tracked, estimated)
+	}
+
 	// Run again to assert idempotency (upsert)
-	if err := uc.Run(ctx, start.Add(-time.Hour), start.Add(4*time.Hour)); err != nil {
+	run, err := uc.Run(ctx, domain.TriggerCLI, start.Add(-time.Hour), start.Add(4*time.Hour))
+	if err != nil {
 		t.Fatalf("sync run 2: %v", err)
 	}
+	if run.Changed() {
+		t.Fatalf("expected idempotent re-sync to report no changes, got %+v", run.Counts)
+	}
 	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entries").Scan(&count); err != nil {
 		t.Fatalf("count 2: %v", err)
 	}
@@ -139,4 +199,751 @@ func TestSyncToMySQL_UpsertsEntries(t *testing.T) {
 	if count != 1 {
 		t.Fatalf("expected 1 project row after upsert, got %d", count)
 	}
+
+	var runs, inserted, unchanged int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sync_runs WHERE status = 'ok' AND trigger_source = 'cli'").Scan(&runs); err != nil {
+		t.Fatalf("sync runs: %v", err)
+	}
+	if runs != 2 {
+		t.Fatalf("expected 2 recorded runs, got %d", runs)
+	}
+	if err := db.QueryRowContext(ctx, `SELECT c.inserted, c.unchanged FROM sync_run_counts c
+JOIN sync_runs r ON r.id = c.run_id
+WHERE c.entity = 'entries' ORDER BY r.id DESC LIMIT 1`).Scan(&inserted, &unchanged); err != nil {
+		t.Fatalf("sync run counts: %v", err)
+	}
+	if inserted != 0 || unchanged != 2 {
+		t.Fatalf("expected second run to leave 2 entries unchanged, got inserted=%d unchanged=%d", inserted, unchanged)
+	}
+
+	// Drop a tag from entry 1 and ensure the join table follows.
+	fake.entries[0].Tags = []string{"dev"}
+	fake.entries[0].TagIDs = []int64{11}
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, start.Add(-time.Hour), start.Add(4*time.Hour)); err != nil {
+		t.Fatalf("sync run 3: %v", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entry_tags WHERE entry_id = 1").Scan(&count); err != nil {
+		t.Fatalf("entry tags count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 tag on entry 1 after removal, got %d", count)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entry_tags").Scan(&count); err != nil {
+		t.Fatalf("all entry tags count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 entry tag rows, got %d", count)
+	}
+}
+
+func TestIncrementalSync_AdvancesWatermark(t *testing.T) {
+	forEachBackend(t, testIncrementalAdvancesWatermark)
+}
+
+func testIncrementalAdvancesWatermark(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+
+	now := time.Date(2025, 8, 2, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-3 * time.Hour)
+	stop := start.Add(time.Hour)
+	fake := fakeToggl{entries: []domain.TimeEntry{
+		{ID: 1, Description: "first", Start: start, Stop: &stop, DurationSec: 3600, At: stop},
+	}}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink, Watermarks: sink, WorkspaceID: 456}
+
+	// Bootstrap: no watermark yet, falls back to the last 24h window.
+	if _, err := uc.RunIncremental(ctx, domain.TriggerInterval, now); err != nil {
+		t.Fatalf("bootstrap run: %v", err)
+	}
+	mark, ok, err := sink.LoadWatermark(ctx, 456)
+	if err != nil || !ok {
+		t.Fatalf("load watermark: ok=%v err=%v", ok, err)
+	}
+	if !mark.Equal(stop) {
+		t.Fatalf("expected watermark %v, got %v", stop, mark)
+	}
+
+	// An edit to an entry started long ago is picked up via since.
+	oldStart := now.AddDate(0, 0, -10)
+	oldStop := oldStart.Add(time.Hour)
+	edited := now.Add(-time.Minute)
+	fake.entries = append(fake.entries, domain.TimeEntry{ID: 2, Description: "old, edited", Start: oldStart, Stop: &oldStop, DurationSec: 3600, At: edited})
+	uc.Toggl = fake
+	if _, err := uc.RunIncremental(ctx, domain.TriggerInterval, now); err != nil {
+		t.Fatalf("incremental run: %v", err)
+	}
+	if mark, _, _ = sink.LoadWatermark(ctx, 456); !mark.Equal(edited) {
+		t.Fatalf("expected watermark %v, got %v", edited, mark)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entries").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 rows, got %d", count)
+	}
+}
+
+func TestSync_SoftDeletesMissingEntries(t *testing.T) {
+	forEachBackend(t, testSoftDeletesMissingEntries)
+}
+
+func testSoftDeletesMissingEntries(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+
+	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	from, to := start.Add(-time.Hour), start.Add(4*time.Hour)
+	fake := fakeToggl{entries: []domain.TimeEntry{
+		{ID: 1, Description: "kept", Start: start, Stop: &stop, DurationSec: 3600},
+		{ID: 2, Description: "deleted in toggl", Start: start.Add(time.Hour), Stop: &stop, DurationSec: 3600},
+	}}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+
+	fake.entries = fake.entries[:1]
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 2: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entries WHERE id = 2 AND deleted_at IS NOT NULL").Scan(&count); err != nil {
+		t.Fatalf("deleted count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected entry 2 to be soft-deleted")
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entries_active").Scan(&count); err != nil {
+		t.Fatalf("active count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 active entry, got %d", count)
+	}
+}
+
+func TestSync_SplitsEntriesIntoDailySegments(t *testing.T) {
+	forEachBackend(t, testSplitsEntriesIntoDailySegments)
+}
+
+func testSplitsEntriesIntoDailySegments(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+	// 21:00-01:00 UTC is 23:00-03:00 in syncTZ: one hour on Aug 1 and
+	// three on Aug 2.
+	start := time.Date(2025, 8, 1, 21, 0, 0, 0, time.UTC)
+	stop := start.Add(4 * time.Hour)
+	from, to := start.Add(-3*time.Hour), start.Add(6*time.Hour)
+	fake := fakeToggl{entries: []domain.TimeEntry{
+		{ID: 1, Description: "late night", Start: start, Stop: &stop, DurationSec: 4 * 3600},
+		{ID: 2, Description: "evening", Start: start.Add(-2 * time.Hour), Stop: &start, DurationSec: 2 * 3600},
+	}}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+	assertSegments(t, ctx, db, 1, 3600, 3*3600)
+	assertSegments(t, ctx, db, 2, 2*3600)
+
+	// Shortening the entry recomputes its segments.
+	shorter := start.Add(30 * time.Minute)
+	fake.entries[0].Stop, fake.entries[0].DurationSec = &shorter, 1800
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 2: %v", err)
+	}
+	assertSegments(t, ctx, db, 1, 1800)
+
+	// Deleting it in Toggl removes them.
+	fake.entries = fake.entries[1:]
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 3: %v", err)
+	}
+	assertSegments(t, ctx, db, 1)
+	assertSegments(t, ctx, db, 2, 2*3600)
+}
+
+func TestSync_RecordsEntryVersions(t *testing.T) {
+	forEachBackend(t, testRecordsEntryVersions)
+}
+
+func testRecordsEntryVersions(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(3 * time.Hour)
+	from, to := start.Add(-time.Hour), start.Add(4*time.Hour)
+	projectA, projectB := int64(1), int64(2)
+	fake := fakeToggl{entries: []domain.TimeEntry{
+		{ID: 1, Description: "invoiced", ProjectID: &projectA, Start: start, Stop: &stop, DurationSec: 3 * 3600},
+	}}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+	// Re-syncing an unchanged entry keeps its version open.
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 2: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	beforeMove := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	// Moving the hours to project B closes the first version.
+	fake.entries[0].ProjectID = &projectB
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 3: %v", err)
+	}
+	var total, open int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*), COUNT(*) - COUNT(valid_to) FROM toggl_time_entry_versions WHERE entry_id = 1",
+	).Scan(&total, &open); err != nil {
+		t.Fatalf("count versions: %v", err)
+	}
+	if total != 2 || open != 1 {
+		t.Fatalf("expected 2 versions with 1 open, got %d and %d", total, open)
+	}
+
+	history, ok := sink.(ports.EntryHistoryReader)
+	if !ok {
+		t.Fatalf("sink does not implement ports.EntryHistoryReader")
+	}
+	asOf := func(at time.Time) []domain.TimeEntry {
+		t.Helper()
+		entries, err := history.ListEntriesAsOf(ctx, at, from, to)
+		if err != nil {
+			t.Fatalf("list entries as of %s: %v", at, err)
+		}
+		return entries
+	}
+	if got := asOf(beforeMove); len(got) != 1 || got[0].ProjectID == nil || *got[0].ProjectID != projectA {
+		t.Fatalf("expected entry on project A before the move, got %+v", got)
+	}
+	if got := asOf(time.Now()); len(got) != 1 || got[0].ProjectID == nil || *got[0].ProjectID != projectB {
+		t.Fatalf("expected entry on project B after the move, got %+v", got)
+	}
+
+	// Deleting the entry in Toggl closes its last version.
+	uc.Toggl = fakeToggl{}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 4: %v", err)
+	}
+	if got := asOf(time.Now()); len(got) != 0 {
+		t.Fatalf("expected no entries after deletion, got %+v", got)
+	}
+	if got := asOf(beforeMove); len(got) != 1 {
+		t.Fatalf("expected history to survive deletion, got %+v", got)
+	}
+}
+
+func TestSync_RefreshesReportingRollups(t *testing.T) {
+	forEachBackend(t, testRefreshesReportingRollups)
+}
+
+func testRefreshesReportingRollups(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+	// Friday Aug 1 2025: one hour on project Alpha, then a late entry
+	// without project that runs into Saturday in syncTZ.
+	start := time.Date(2025, 8, 1, 7, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	late := time.Date(2025, 8, 1, 21, 0, 0, 0, time.UTC)
+	lateStop := late.Add(4 * time.Hour)
+	from, to := start.Add(-time.Hour), lateStop.Add(time.Hour)
+	clientID, projectID := int64(7), int64(1)
+	fake := fakeToggl{
+		entries: []domain.TimeEntry{
+			{ID: 1, Description: "alpha", ProjectID: &projectID, Tags: []string{"dev"}, TagIDs: []int64{3}, Start: start, Stop: &stop, DurationSec: 3600},
+			{ID: 2, Description: "late", Start: late, Stop: &lateStop, DurationSec: 4 * 3600},
+		},
+		projects: []domain.Project{{ID: projectID, WorkspaceID: 1, Name: "Alpha", Active: true, ClientID: &clientID, At: start}},
+		clients:  []domain.Client{{ID: clientID, WorkspaceID: 1, Name: "Acme", At: start}},
+		tags:     []domain.Tag{{ID: 3, WorkspaceID: 1, Name: "dev", At: start}},
+	}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+
+	var project, client string
+	if err := db.QueryRowContext(ctx, "SELECT project_name, client_name FROM toggl_report_entries WHERE id = 1").Scan(&project, &client); err != nil {
+		t.Fatalf("report entries: %v", err)
+	}
+	if project != "Alpha" || client != "Acme" {
+		t.Fatalf("expected Alpha/Acme, got %s/%s", project, client)
+	}
+	var tag string
+	var tagged int
+	if err := db.QueryRowContext(ctx, "SELECT tag_name, entries FROM toggl_report_tag_totals").Scan(&tag, &tagged); err != nil {
+		t.Fatalf("tag totals: %v", err)
+	}
+	if tag != "dev" || tagged != 1 {
+		t.Fatalf("expected 1 entry tagged dev, got %d tagged %s", tagged, tag)
+	}
+	assertRollup(t, ctx, db, "toggl_report_project_daily", 3600, 3600, 3*3600)
+	// Friday and Saturday share the week starting Monday Jul 28.
+	assertRollup(t, ctx, db, "toggl_report_project_weekly", 3600, 4*3600)
+	assertRollup(t, ctx, db, "toggl_report_project_monthly", 3600, 4*3600)
+
+	// Deleting the late entry in Toggl refreshes the rollups.
+	fake.entries = fake.entries[:1]
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, to); err != nil {
+		t.Fatalf("sync run 2: %v", err)
+	}
+	assertRollup(t, ctx, db, "toggl_report_project_daily", 3600)
+	assertRollup(t, ctx, db, "toggl_report_project_weekly", 3600)
+
+	// Only the periods a batch touches are refreshed: a row for a day no
+	// entry covers survives, while moving the entry to September clears
+	// its old August periods.
+	if _, err := db.ExecContext(ctx, "INSERT INTO toggl_rollup_daily (day, project_id, seconds, entries) VALUES ('2025-01-01', 0, 42, 1)"); err != nil {
+		t.Fatalf("insert stray rollup: %v", err)
+	}
+	moved := time.Date(2025, 9, 2, 7, 0, 0, 0, time.UTC)
+	movedStop := moved.Add(time.Hour)
+	fake.entries[0].Start, fake.entries[0].Stop = moved, &movedStop
+	uc.Toggl = fake
+	if _, err := uc.Run(ctx, domain.TriggerCLI, from, movedStop.Add(time.Hour)); err != nil {
+		t.Fatalf("sync run 3: %v", err)
+	}
+	assertRollup(t, ctx, db, "toggl_report_project_daily", 42, 3600)
+	assertRollup(t, ctx, db, "toggl_report_project_weekly", 3600)
+	assertRollup(t, ctx, db, "toggl_report_project_monthly", 3600)
+	var month string
+	if err := db.QueryRowContext(ctx, "SELECT month_start FROM toggl_rollup_monthly").Scan(&month); err != nil {
+		t.Fatalf("monthly rollup: %v", err)
+	}
+	if !strings.HasPrefix(month, "2025-09-01") {
+		t.Fatalf("expected the September rollup only, got %s", month)
+	}
+}
+
+func TestMigrate_BackfillsRollups(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "toggl.db")
+	sink := openSQLiteStore(t, ctx, path, logger)
+	start := time.Date(2025, 8, 1, 7, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	nextStop := stop.Add(49 * time.Hour)
+	projectID := int64(1)
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fakeToggl{entries: []domain.TimeEntry{
+		{ID: 1, ProjectID: &projectID, Start: start, Stop: &stop, DurationSec: 3600},
+		{ID: 2, Start: nextStop.Add(-time.Hour), Stop: &nextStop, DurationSec: 3600},
+	}}, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, start, nextStop); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+
+	// Upgrading a database that has segments but predates the rollups
+	// fills them from those segments.
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	for _, q := range []string{
+		"DROP TABLE toggl_rollup_daily",
+		"DROP TABLE toggl_rollup_weekly",
+		"DROP TABLE toggl_rollup_monthly",
+		"DELETE FROM schema_migrations WHERE version = 13",
+	} {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	if err := migrate.Run(ctx, migrate.SQLite, path, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	assertRollup(t, ctx, db, "toggl_report_project_daily", 3600, 3600)
+	// Friday Aug 1 and Sunday Aug 3 share a week; both are in August.
+	assertRollup(t, ctx, db, "toggl_report_project_weekly", 3600, 3600)
+	assertRollup(t, ctx, db, "toggl_report_project_monthly", 3600, 3600)
+	var weeks, months int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT week_start), COUNT(DISTINCT month_start) FROM toggl_rollup_weekly, toggl_rollup_monthly").Scan(&weeks, &months); err != nil {
+		t.Fatalf("count periods: %v", err)
+	}
+	if weeks != 1 || months != 1 {
+		t.Fatalf("expected one week and one month, got %d and %d", weeks, months)
+	}
+}
+
+// assertRollup checks the seconds column of a rollup view, in ascending
+// order.
+func assertRollup(t *testing.T, ctx context.Context, db *sql.DB, view string, want ...int64) {
+	t.Helper()
+	rows, err := db.QueryContext(ctx, "SELECT seconds FROM "+view+" ORDER BY seconds")
+	if err != nil {
+		t.Fatalf("query %s: %v", view, err)
+	}
+	defer rows.Close()
+	var got []int64
+	for rows.Next() {
+		var s int64
+		if err := rows.Scan(&s); err != nil {
+			t.Fatalf("scan %s: %v", view, err)
+		}
+		got = append(got, s)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("%s: expected seconds %v, got %v", view, want, got)
+	}
+}
+
+func TestSync_ReconcilesRunningTimers(t *testing.T) {
+	forEachBackend(t, testReconcilesRunningTimers)
+}
+
+func testReconcilesRunningTimers(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+	now := time.Now().UTC().Truncate(time.Second)
+	start := now.Add(-2 * time.Hour)
+	doneStart := now.Add(-3 * time.Hour)
+	doneStop := doneStart.Add(30 * time.Minute)
+	fake := fakeToggl{entries: []domain.TimeEntry{
+		// Toggl reports a running timer with duration -start and no stop.
+		{ID: 1, Description: "running", Tags: []string{"dev", "ops"}, TagIDs: []int64{3, 4}, Start: start, DurationSec: -start.Unix()},
+		{ID: 2, Description: "done", Start: doneStart, Stop: &doneStop, DurationSec: 1800},
+	}}
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink, Running: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, now.Add(-4*time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+	var running bool
+	var duration int64
+	if err := db.QueryRowContext(ctx, "SELECT is_running, duration_sec FROM toggl_time_entries WHERE id = 1").Scan(&running, &duration); err != nil {
+		t.Fatalf("running entry: %v", err)
+	}
+	if !running || duration < 2*3600 || duration > 2*3600+300 {
+		t.Fatalf("expected a running entry with a provisional 2h duration, got running=%v duration=%d", running, duration)
+	}
+	// Entries read back keep their running flag and tag IDs.
+	stored, err := sink.ListRunningEntries(ctx)
+	if err != nil {
+		t.Fatalf("list running: %v", err)
+	}
+	if len(stored) != 1 || !stored[0].Running || fmt.Sprint(stored[0].TagIDs) != "[3 4]" {
+		t.Fatalf("expected running entry 1 with tag IDs [3 4], got %+v", stored)
+	}
+	// Running timers stay out of segments, rollups and history.
+	assertSegments(t, ctx, db, 1)
+	assertRollup(t, ctx, db, "toggl_report_project_daily", 1800)
+	var versions int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entry_versions WHERE entry_id = 1").Scan(&versions); err != nil {
+		t.Fatalf("count versions: %v", err)
+	}
+	if versions != 0 {
+		t.Fatalf("expected no versions for a running timer, got %d", versions)
+	}
+
+	// The timer stops. A window that does not cover its start still
+	// reconciles it to the final stop time.
+	stop := start.Add(150 * time.Minute)
+	fake.entries[0].Stop, fake.entries[0].DurationSec = &stop, 9000
+	uc.Toggl = fake
+	run, err := uc.Run(ctx, domain.TriggerCLI, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("sync run 2: %v", err)
+	}
+	if got := run.Counts["entries"].Updated; got != 1 {
+		t.Fatalf("expected the timer to be updated, got %+v", run.Counts["entries"])
+	}
+	if err := db.QueryRowContext(ctx, "SELECT is_running, duration_sec FROM toggl_time_entries WHERE id = 1").Scan(&running, &duration); err != nil {
+		t.Fatalf("stopped entry: %v", err)
+	}
+	if running || duration != 9000 {
+		t.Fatalf("expected a stopped 9000s entry, got running=%v duration=%d", running, duration)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entry_versions WHERE entry_id = 1").Scan(&versions); err != nil {
+		t.Fatalf("count versions: %v", err)
+	}
+	if versions != 1 {
+		t.Fatalf("expected one version once stopped, got %d", versions)
+	}
+}
+
+func TestSync_SplitsLargeJoinTableInserts(t *testing.T) {
+	forEachBackend(t, testSplitsLargeJoinTableInserts)
+}
+
+func testSplitsLargeJoinTableInserts(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+	// A full batch of heavily tagged entries and a user in many groups both
+	// need more placeholders than any backend allows in one statement.
+	const entries, tagsPerEntry, groups = 500, 40, 40000
+	start := time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+	tagIDs := make([]int64, tagsPerEntry)
+	for i := range tagIDs {
+		tagIDs[i] = int64(i + 1)
+	}
+	var fake fakeToggl
+	for i := range entries {
+		s := start.Add(time.Duration(i) * time.Minute)
+		stop := s.Add(time.Minute)
+		fake.entries = append(fake.entries, domain.TimeEntry{ID: int64(i + 1), Start: s, Stop: &stop, DurationSec: 60, TagIDs: tagIDs})
+	}
+	user := domain.User{ID: 1, WorkspaceID: 1, Name: "Ada", Active: true}
+	for i := range groups {
+		user.GroupIDs = append(user.GroupIDs, int64(i+1))
+	}
+	fake.users = []domain.User{user}
+
+	uc := &usecase.SyncUseCase{Log: logger, Toggl: fake, Sink: sink}
+	if _, err := uc.Run(ctx, domain.TriggerCLI, start, start.Add(24*time.Hour)); err != nil {
+		t.Fatalf("sync run: %v", err)
+	}
+	var tags, members int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_time_entry_tags").Scan(&tags); err != nil {
+		t.Fatalf("count tags: %v", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM toggl_group_members").Scan(&members); err != nil {
+		t.Fatalf("count members: %v", err)
+	}
+	if tags != entries*tagsPerEntry || members != groups {
+		t.Fatalf("expected %d entry tags and %d group members, got %d and %d", entries*tagsPerEntry, groups, tags, members)
+	}
+}
+
+func TestJobs_StoresFinishedJobs(t *testing.T) {
+	forEachBackend(t, testStoresFinishedJobs)
+}
+
+func testStoresFinishedJobs(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB) {
+	now := time.Now().UTC().Truncate(time.Second)
+	if _, ok, err := sink.LoadJob(ctx, "missing"); err != nil || ok {
+		t.Fatalf("expected no job, got ok=%v err=%v", ok, err)
+	}
+
+	job := domain.Job{
+		ID:        "job-1",
+		State:     domain.JobCancelled,
+		Mode:      "incremental",
+		CreatedAt: now.Add(-time.Minute),
+	}
+	if err := sink.SaveJob(ctx, job); err != nil {
+		t.Fatalf("save job: %v", err)
+	}
+	got, ok, err := sink.LoadJob(ctx, job.ID)
+	if err != nil || !ok {
+		t.Fatalf("load job: ok=%v err=%v", ok, err)
+	}
+	if got.State != domain.JobCancelled || !got.StartedAt.IsZero() || got.Counts != nil || !got.CreatedAt.Equal(job.CreatedAt) {
+		t.Fatalf("unexpected job %+v", got)
+	}
+
+	// Saving again replaces the stored job.
+	job.State = domain.JobError
+	job.Mode = "window"
+	job.From, job.To = now.Add(-24*time.Hour), now
+	job.RunID = 7
+	job.Stage = "entries"
+	job.Counts = map[string]domain.SyncCounts{"entries": {Fetched: 3, Inserted: 2, Unchanged: 1}}
+	job.Sinks = map[string]domain.SinkResult{"file": {Error: "disk full"}}
+	job.Error = "toggl unavailable"
+	job.StartedAt, job.FinishedAt = now.Add(-30*time.Second), now
+	if err := sink.SaveJob(ctx, job); err != nil {
+		t.Fatalf("save job again: %v", err)
+	}
+	got, ok, err = sink.LoadJob(ctx, job.ID)
+	if err != nil || !ok {
+		t.Fatalf("load job again: ok=%v err=%v", ok, err)
+	}
+	if got.State != domain.JobError || got.Mode != "window" || got.RunID != 7 || got.Error != "toggl unavailable" {
+		t.Fatalf("unexpected job %+v", got)
+	}
+	if !got.From.Equal(job.From) || !got.To.Equal(job.To) || !got.StartedAt.Equal(job.StartedAt) || !got.FinishedAt.Equal(job.FinishedAt) {
+		t.Fatalf("unexpected job times %+v", got)
+	}
+	if got.Counts["entries"] != job.Counts["entries"] || got.Sinks["file"].Error != "disk full" {
+		t.Fatalf("unexpected job results counts=%+v sinks=%+v", got.Counts, got.Sinks)
+	}
+}
+
+// assertSegments checks an entry's daily segment seconds in day order.
+func assertSegments(t *testing.T, ctx context.Context, db *sql.DB, entryID int64, want ...int64) {
+	t.Helper()
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT seconds FROM toggl_daily_entry_segments WHERE entry_id = %d ORDER BY day", entryID))
+	if err != nil {
+		t.Fatalf("query segments: %v", err)
+	}
+	defer rows.Close()
+	var got []int64
+	for rows.Next() {
+		var s int64
+		if err := rows.Scan(&s); err != nil {
+			t.Fatalf("scan segment: %v", err)
+		}
+		got = append(got, s)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("entry %d: expected segments %v, got %v", entryID, want, got)
+	}
+}
+
+// store is a database sink that also keeps watermarks, run and job history
+// and tracks running timers.
+type store interface {
+	ports.Sink
+	ports.WatermarkStore
+	ports.RunRecorder
+	ports.RunningEntryReader
+	ports.JobStore
+	Close() error
+}
+
+// backend is a database the e2e suite runs against.
+type backend struct {
+	name    string
+	dialect string // migrate dialect
+	driver  string // database/sql driver for assertions
+	start   func(t testing.TB, ctx context.Context) string
+	open    func(ctx context.Context, dsn string, loc *time.Location, log *slog.Logger) (store, error)
+	// docker is set for backends started as containers; they are skipped
+	// when no Docker daemon is reachable.
+	docker bool
+}
+
+var backends = []backend{
+	{
+		name:    "mysql",
+		dialect: migrate.MySQL,
+		driver:  "mysql",
+		start:   startMySQL,
+		open: func(ctx context.Context, dsn string, loc *time.Location, log *slog.Logger) (store, error) {
+			return msql.NewClient(ctx, dsn, msql.DefaultBatchSize, loc, log)
+		},
+		docker: true,
+	},
+	{
+		name:    "postgres",
+		dialect: migrate.Postgres,
+		driver:  "pgx",
+		start:   startPostgres,
+		open: func(ctx context.Context, dsn string, loc *time.Location, log *slog.Logger) (store, error) {
+			return pg.NewClient(ctx, dsn, pg.DefaultBatchSize, loc, log)
+		},
+		docker: true,
+	},
+	{
+		name:    "sqlite",
+		dialect: migrate.SQLite,
+		driver:  "sqlite",
+		start:   startSQLite,
+		open: func(ctx context.Context, dsn string, loc *time.Location, log *slog.Logger) (store, error) {
+			return sqlite.NewClient(ctx, dsn, sqlite.DefaultBatchSize, loc, log)
+		},
+	},
+}
+
+// syncTZ is the SYNC_TZ sinks under test split daily segments in.
+var syncTZ = time.FixedZone("UTC+2", 2*60*60)
+
+// forEachBackend runs test as a subtest against a freshly migrated
+// container of every backend, passing the sink and a raw connection for
+// assertions.
+func forEachBackend(t *testing.T, test func(t *testing.T, ctx context.Context, logger *slog.Logger, sink store, db *sql.DB)) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			if b.docker {
+				testcontainers.SkipIfProviderIsNotHealthy(t)
+			}
+			ctx := context.Background()
+			dsn := b.start(t, ctx)
+
+			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+			if err := migrate.Run(ctx, b.dialect, dsn, logger); err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+			sink, err := b.open(ctx, dsn, syncTZ, logger)
+			if err != nil {
+				t.Fatalf("%s client: %v", b.name, err)
+			}
+			t.Cleanup(func() { _ = sink.Close() })
+
+			db, err := sql.Open(b.driver, dsn)
+			if err != nil {
+				t.Fatalf("sql open: %v", err)
+			}
+			t.Cleanup(func() { _ = db.Close() })
+
+			test(t, ctx, logger, sink, db)
+		})
+	}
+}
+
+// startMySQL starts a MySQL container and returns a DSN for it.
+func startMySQL(t testing.TB, ctx context.Context) string {
+	t.Helper()
+	req := testcontainers.ContainerRequest{
+		Image:        "mysql:8.0",
+		ExposedPorts: []string{"3306/tcp"},
+		Env: map[string]string{
+			"MYSQL_DATABASE":      "testdb",
+			"MYSQL_ROOT_PASSWORD": "secret",
+			"MYSQL_USER":          "test",
+			"MYSQL_PASSWORD":      "pass",
+		},
+		WaitingFor: wait.ForListeningPort("3306/tcp").WithStartupTimeout(90 * time.Second),
+	}
+	mysqlC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start mysql container: %v", err)
+	}
+	t.Cleanup(func() { _ = mysqlC.Terminate(context.Background()) })
+
+	host, err := mysqlC.Host(ctx)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	port, err := mysqlC.MappedPort(ctx, "3306/tcp")
+	if err != nil {
+		t.Fatalf("mapped port: %v", err)
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true", "test", "pass", host, port.Port(), "testdb")
+}
+
+// startPostgres starts a PostgreSQL container and returns a DSN for it.
+func startPostgres(t testing.TB, ctx context.Context) string {
+	t.Helper()
+	req := testcontainers.ContainerRequest{
+		Image:        "postgres:16",
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_DB":       "testdb",
+			"POSTGRES_USER":     "test",
+			"POSTGRES_PASSWORD": "pass",
+		},
+		// The entrypoint restarts the server once after initdb, so wait
+		// for the second ready message.
+		WaitingFor: wait.ForLog("database system is ready to accept connections").
+			WithOccurrence(2).
+			WithStartupTimeout(90 * time.Second),
+	}
+	pgC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start postgres container: %v", err)
+	}
+	t.Cleanup(func() { _ = pgC.Terminate(context.Background()) })
+
+	host, err := pgC.Host(ctx)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	port, err := pgC.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		t.Fatalf("mapped port: %v", err)
+	}
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", "test", "pass", host, port.Port(), "testdb")
+}
+
+// startSQLite returns the path of a fresh SQLite database file.
+func startSQLite(t testing.TB, ctx context.Context) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "toggl.db")
 }
@@ -0,0 +1,234 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tg "toggl-scraper/internal/adapter/toggl"
+	"toggl-scraper/internal/domain"
+)
+
+// togglEntry is a time entry served by fakeTogglAPI.
+type togglEntry struct {
+	ID          int64     `json:"id"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	Duration    int64     `json:"duration"`
+	At          time.Time `json:"at"`
+}
+
+// fakeTogglAPI serves /api/v9/me/time_entries from entries, filtering by
+// start_date/end_date, before and since like Toggl and capping every
+// response at 1000 rows.
+type fakeTogglAPI struct {
+	entries []togglEntry
+
+	mu      sync.Mutex
+	queries []url.Values
+}
+
+func (f *fakeTogglAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v9/me/time_entries" {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+	f.mu.Lock()
+	f.queries = append(f.queries, q)
+	f.mu.Unlock()
+
+	parse := func(name string) time.Time {
+		t, _ := time.Parse(time.RFC3339, q.Get(name))
+		return t
+	}
+	from, to, before := parse("start_date"), parse("end_date"), parse("before")
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		n, _ := strconv.ParseInt(v, 10, 64)
+		since = time.Unix(n, 0)
+	}
+	out := []togglEntry{}
+	for _, e := range f.entries {
+		if !from.IsZero() && (e.Start.Before(from) || !e.Start.Before(to)) {
+			continue
+		}
+		if !before.IsZero() && !e.Start.Before(before) {
+			continue
+		}
+		if !since.IsZero() && e.At.Before(since) {
+			continue
+		}
+		out = append(out, e)
+		if len(out) == 1000 {
+			break
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// windows returns the start_date/end_date pairs requested so far.
+func (f *fakeTogglAPI) windows(t *testing.T) [][2]time.Time {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out [][2]time.Time
+	for _, q := range f.queries {
+		if q.Get("start_date") == "" {
+			continue
+		}
+		from, err := time.Parse(time.RFC3339, q.Get("start_date"))
+		if err != nil {
+			t.Fatalf("start_date: %v", err)
+		}
+		to, err := time.Parse(time.RFC3339, q.Get("end_date"))
+		if err != nil {
+			t.Fatalf("end_date: %v", err)
+		}
+		out = append(out, [2]time.Time{from, to})
+	}
+	return out
+}
+
+// newTestTogglClient returns a client for srv without retry delays.
+func newTestTogglClient(srv *httptest.Server, workspaceID int64) *tg.Client {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	retry := tg.RetryPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	return tg.NewClient(srv.URL, "token", workspaceID, retry, logger)
+}
+
+// spreadEntries returns n entries with consecutive IDs from firstID, their
+// starts spread evenly over [from, from+span).
+func spreadEntries(firstID int64, n int, from time.Time, span time.Duration) []togglEntry {
+	out := make([]togglEntry, 0, n)
+	for i := 0; i < n; i++ {
+		start := from.Add(span * time.Duration(i) / time.Duration(n))
+		out = append(out, togglEntry{ID: firstID + int64(i), Start: start, At: start})
+	}
+	return out
+}
+
+func TestTogglClient_ChunksWindowsIntoWeeks(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(20 * 24 * time.Hour)
+	api := &fakeTogglAPI{entries: spreadEntries(1, 40, from, 20*24*time.Hour)}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	entries, err := newTestTogglClient(srv, 0).ListTimeEntries(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 40 {
+		t.Fatalf("expected 40 entries, got %d", len(entries))
+	}
+	windows := api.windows(t)
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 weekly requests, got %v", windows)
+	}
+	next := from
+	for _, w := range windows {
+		if !w[0].Equal(next) || w[1].Sub(w[0]) > 7*24*time.Hour {
+			t.Fatalf("unexpected chunk %v after %v", w, next)
+		}
+		next = w[1]
+	}
+	if !next.Equal(to) {
+		t.Fatalf("expected chunks to end at %v, got %v", to, next)
+	}
+}
+
+func TestTogglClient_BisectsTruncatedWindows(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	api := &fakeTogglAPI{entries: spreadEntries(1, 2500, from, 7*24*time.Hour)}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	entries, err := newTestTogglClient(srv, 0).ListTimeEntries(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	assertEntryIDs(t, entries, 2500)
+	if n := len(api.windows(t)); n < 5 {
+		t.Fatalf("expected the week to be bisected, got %d requests", n)
+	}
+}
+
+func TestTogglClient_TruncatedAtMinimumChunk(t *testing.T) {
+	from := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	// More entries than the cap within half an hour cannot be split
+	// below an hour.
+	api := &fakeTogglAPI{entries: spreadEntries(1, 1200, from, 30*time.Minute)}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	_, err := newTestTogglClient(srv, 0).ListTimeEntries(context.Background(), from.Add(-time.Hour), from.Add(2*time.Hour))
+	var truncErr *tg.TruncatedError
+	if !errors.As(err, &truncErr) {
+		t.Fatalf("expected *TruncatedError, got %v", err)
+	}
+	if truncErr.To.Sub(truncErr.From) > time.Hour || truncErr.Count != 1000 {
+		t.Fatalf("unexpected truncation %+v", truncErr)
+	}
+}
+
+func TestTogglClient_ChunksTruncatedSinceQueries(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	since := now.Add(-time.Hour)
+	// 1500 entries edited in the last hour, started over the last month,
+	// plus one edited long ago that must not be returned.
+	entries := spreadEntries(1, 1500, now.Add(-30*24*time.Hour), 30*24*time.Hour)
+	for i := range entries {
+		entries[i].At = now.Add(-time.Minute)
+	}
+	stale := togglEntry{ID: 9999, Start: now.Add(-48 * time.Hour), At: now.Add(-48 * time.Hour)}
+	api := &fakeTogglAPI{entries: append(entries, stale)}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	got, err := newTestTogglClient(srv, 0).ListTimeEntriesSince(context.Background(), since)
+	if err != nil {
+		t.Fatalf("list since: %v", err)
+	}
+	assertEntryIDs(t, got, 1500)
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	for _, q := range api.queries {
+		if q.Get("since") == "" {
+			t.Fatalf("expected every request to keep since, got %v", q)
+		}
+	}
+}
+
+// assertEntryIDs checks that entries holds IDs 1..n, each exactly once.
+func assertEntryIDs(t *testing.T, entries []domain.TimeEntry, n int) {
+	t.Helper()
+	seen := make(map[int64]bool, len(entries))
+	for _, e := range entries {
+		if seen[e.ID] {
+			t.Fatalf("entry %d returned twice", e.ID)
+		}
+		seen[e.ID] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d entries, got %d", n, len(seen))
+	}
+	for id := int64(1); id <= int64(n); id++ {
+		if !seen[id] {
+			t.Fatalf("entry %d missing", id)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/ports"
+)
+
+// Policy decides what a target's failure means for the run.
+type Policy int
+
+const (
+	// Required targets fail the run when a write fails.
+	Required Policy = iota
+	// Optional targets only log failures; the run continues.
+	Optional
+)
+
+// Target is one sink of a fan-out.
+type Target struct {
+	Name   string
+	Sink   ports.Sink
+	Policy Policy
+}
+
+// Sink implements ports.Sink by forwarding every call to each target in
+// order. Counts returned to the caller are those of the first target;
+// TakeResults reports every target's counts and failures.
+type Sink struct {
+	log     *slog.Logger
+	targets []Target
+
+	mu      sync.Mutex
+	results map[string]domain.SinkResult
+}
+
+// New returns a fan-out over targets, which must not be empty.
+func New(log *slog.Logger, targets ...Target) (*Sink, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("fanout: at least one target is required")
+	}
+	return &Sink{log: log, targets: targets}, nil
+}
+
+// forward calls write for every target, records each outcome under entity
+// and returns the first target's counts. Every target is attempted even
+// after a failure; failures of Required targets are joined into the error.
+func (s *Sink) forward(entity string, write func(ports.Sink) (domain.SyncCounts, error)) (domain.SyncCounts, error) {
+	var (
+		first domain.SyncCounts
+		errs  []error
+	)
+	for i, t := range s.targets {
+		counts, err := write(t.Sink)
+		s.record(t.Name, entity, counts, err)
+		if i == 0 {
+			first = counts
+		}
+		if err == nil {
+			continue
+		}
+		if t.Policy == Required {
+			errs = append(errs, fmt.Errorf("sink %s: %w", t.Name, err))
+			continue
+		}
+		s.log.Warn("optional sink failed",
+			slog.String("sink", t.Name),
+			slog.String("entity", entity),
+			slog.String("error", err.Error()),
+		)
+	}
+	return first, errors.Join(errs...)
+}
+
+func (s *Sink) record(name, entity string, counts domain.SyncCounts, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.results == nil {
+		s.results = make(map[string]domain.SinkResult, len(s.targets))
+	}
+	res := s.results[name]
+	if res.Counts == nil {
+		res.Counts = make(map[string]domain.SyncCounts)
+	}
+	c := res.Counts[entity]
+	c.Add(counts)
+	res.Counts[entity] = c
+	if err != nil && res.Error == "" {
+		res.Error = err.Error()
+	}
+	s.results[name] = res
+}
+
+// TakeResults returns the per-target results gathered since the last call
+// and resets them.
+func (s *Sink) TakeResults() map[string]domain.SinkResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := s.results
+	s.results = nil
+	return res
+}
+
+// SyncEntries forwards entries to every target.
+func (s *Sink) SyncEntries(ctx context.Context, entries []domain.TimeEntry) (domain.SyncCounts, error) {
+	return s.forward("entries", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncEntries(ctx, entries)
+	})
+}
+
+// MarkMissingDeleted forwards the soft-delete to every target and returns
+// the first target's count.
+func (s *Sink) MarkMissingDeleted(ctx context.Context, from, to time.Time, workspaceID int64, present []int64) (int, error) {
+	counts, err := s.forward("entries", func(t ports.Sink) (domain.SyncCounts, error) {
+		n, err := t.MarkMissingDeleted(ctx, from, to, workspaceID, present)
+		return domain.SyncCounts{Deleted: n}, err
+	})
+	return counts.Deleted, err
+}
+
+// SyncProjects forwards projects to every target.
+func (s *Sink) SyncProjects(ctx context.Context, projects []domain.Project) (domain.SyncCounts, error) {
+	return s.forward("projects", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncProjects(ctx, projects)
+	})
+}
+
+// SyncClients forwards clients to every target.
+func (s *Sink) SyncClients(ctx context.Context, clients []domain.Client) (domain.SyncCounts, error) {
+	return s.forward("clients", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncClients(ctx, clients)
+	})
+}
+
+// SyncTags forwards tags to every target.
+func (s *Sink) SyncTags(ctx context.Context, tags []domain.Tag) (domain.SyncCounts, error) {
+	return s.forward("tags", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncTags(ctx, tags)
+	})
+}
+
+// SyncTasks forwards tasks to every target.
+func (s *Sink) SyncTasks(ctx context.Context, tasks []domain.Task) (domain.SyncCounts, error) {
+	return s.forward("tasks", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncTasks(ctx, tasks)
+	})
+}
+
+// SyncUsers forwards users to every target.
+func (s *Sink) SyncUsers(ctx context.Context, users []domain.User) (domain.SyncCounts, error) {
+	return s.forward("users", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncUsers(ctx, users)
+	})
+}
+
+// SyncGroups forwards groups to every target.
+func (s *Sink) SyncGroups(ctx context.Context, groups []domain.Group) (domain.SyncCounts, error) {
+	return s.forward("groups", func(t ports.Sink) (domain.SyncCounts, error) {
+		return t.SyncGroups(ctx, groups)
+	})
+}
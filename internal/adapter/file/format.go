@@ -0,0 +1,381 @@
+package file
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// Format is a file encoding supported by the sink and the exporter.
+type Format string
+
+const (
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+)
+
+// ParseFormat validates a format name; empty selects NDJSON.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", NDJSON:
+		return NDJSON, nil
+	case CSV:
+		return CSV, nil
+	default:
+		return "", fmt.Errorf("file: unsupported format %q (want ndjson or csv)", s)
+	}
+}
+
+// ext returns the file name extension for f.
+func (f Format) ext() string { return "." + string(f) }
+
+// entryRecord is the file layout of a time entry. Field order is the
+// column order of both formats and must match entryColumns.
+type entryRecord struct {
+	ID          int64      `json:"id"`
+	WorkspaceID *int64     `json:"workspace_id"`
+	ProjectID   *int64     `json:"project_id"`
+	TaskID      *int64     `json:"task_id"`
+	UserID      *int64     `json:"user_id"`
+	Description string     `json:"description"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop"`
+	DurationSec int64      `json:"duration_sec"`
+	Billable    bool       `json:"billable"`
+	Tags        []string   `json:"tags"`
+	At          *time.Time `json:"at"`
+	CreatedWith string     `json:"created_with"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	// IsRunning marks a timer running at sync time, whose stop is empty
+	// and duration provisional.
+	IsRunning bool `json:"is_running"`
+}
+
+var entryColumns = []string{
+	"id", "workspace_id", "project_id", "task_id", "user_id", "description", "start", "stop",
+	"duration_sec", "billable", "tags", "at", "created_with", "deleted_at", "is_running",
+}
+
+func newEntryRecord(e domain.TimeEntry) entryRecord {
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	var at *time.Time
+	if !e.At.IsZero() {
+		at = utc(&e.At)
+	}
+	return entryRecord{
+		ID:          e.ID,
+		WorkspaceID: e.WorkspaceID,
+		ProjectID:   e.ProjectID,
+		TaskID:      e.TaskID,
+		UserID:      e.UserID,
+		Description: e.Description,
+		Start:       e.Start.UTC(),
+		Stop:        utc(e.Stop),
+		DurationSec: e.DurationSec,
+		Billable:    e.Billable,
+		Tags:        tags,
+		At:          at,
+		CreatedWith: e.CreatedWith,
+		DeletedAt:   utc(e.DeletedAt),
+		IsRunning:   e.Running,
+	}
+}
+
+func (r entryRecord) id() int64 { return r.ID }
+
+func (r entryRecord) row() []string {
+	tags, _ := json.Marshal(r.Tags)
+	return []string{
+		strconv.FormatInt(r.ID, 10), formatInt(r.WorkspaceID), formatInt(r.ProjectID), formatInt(r.TaskID),
+		formatInt(r.UserID), r.Description, r.Start.Format(time.RFC3339Nano), formatTime(r.Stop),
+		strconv.FormatInt(r.DurationSec, 10), strconv.FormatBool(r.Billable), string(tags), formatTime(r.At),
+		r.CreatedWith, formatTime(r.DeletedAt), strconv.FormatBool(r.IsRunning),
+	}
+}
+
+func parseEntryRow(row []string) (entryRecord, error) {
+	var (
+		r entryRecord
+		p rowParser
+	)
+	r.ID = p.int(row[0])
+	r.WorkspaceID = p.optInt(row[1])
+	r.ProjectID = p.optInt(row[2])
+	r.TaskID = p.optInt(row[3])
+	r.UserID = p.optInt(row[4])
+	r.Description = row[5]
+	r.Start = p.time(row[6])
+	r.Stop = p.optTime(row[7])
+	r.DurationSec = p.int(row[8])
+	r.Billable = p.bool(row[9])
+	if p.err == nil {
+		p.err = json.Unmarshal([]byte(row[10]), &r.Tags)
+	}
+	r.At = p.optTime(row[11])
+	r.CreatedWith = row[12]
+	r.DeletedAt = p.optTime(row[13])
+	r.IsRunning = p.bool(row[14])
+	return r, p.err
+}
+
+// projectRecord is the file layout of a project; field order must match
+// projectColumns.
+type projectRecord struct {
+	ID          int64     `json:"id"`
+	WorkspaceID int64     `json:"workspace_id"`
+	ClientID    *int64    `json:"client_id"`
+	Name        string    `json:"name"`
+	Active      bool      `json:"active"`
+	Private     bool      `json:"is_private"`
+	Color       string    `json:"color"`
+	At          time.Time `json:"at"`
+}
+
+var projectColumns = []string{"id", "workspace_id", "client_id", "name", "active", "is_private", "color", "at"}
+
+func newProjectRecord(p domain.Project) projectRecord {
+	return projectRecord{
+		ID:          p.ID,
+		WorkspaceID: p.WorkspaceID,
+		ClientID:    p.ClientID,
+		Name:        p.Name,
+		Active:      p.Active,
+		Private:     p.Private,
+		Color:       p.Color,
+		At:          p.At.UTC(),
+	}
+}
+
+func (r projectRecord) id() int64 { return r.ID }
+
+func (r projectRecord) row() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10), strconv.FormatInt(r.WorkspaceID, 10), formatInt(r.ClientID), r.Name,
+		strconv.FormatBool(r.Active), strconv.FormatBool(r.Private), r.Color, r.At.Format(time.RFC3339Nano),
+	}
+}
+
+func parseProjectRow(row []string) (projectRecord, error) {
+	var (
+		r projectRecord
+		p rowParser
+	)
+	r.ID = p.int(row[0])
+	r.WorkspaceID = p.int(row[1])
+	r.ClientID = p.optInt(row[2])
+	r.Name = row[3]
+	r.Active = p.bool(row[4])
+	r.Private = p.bool(row[5])
+	r.Color = row[6]
+	r.At = p.time(row[7])
+	return r, p.err
+}
+
+// record is implemented by entryRecord and projectRecord.
+type record interface {
+	id() int64
+	row() []string
+}
+
+// codec describes how a record type maps to CSV rows.
+type codec[R record] struct {
+	columns []string
+	parse   func([]string) (R, error)
+}
+
+var (
+	entryCodec   = codec[entryRecord]{columns: entryColumns, parse: parseEntryRow}
+	projectCodec = codec[projectRecord]{columns: projectColumns, parse: parseProjectRow}
+)
+
+// WriteEntries encodes entries to w in the given format. CSV output starts
+// with a header row; columns are always in the same order.
+func WriteEntries(w io.Writer, format Format, entries []domain.TimeEntry) error {
+	recs := make([]entryRecord, len(entries))
+	for i, e := range entries {
+		recs[i] = newEntryRecord(e)
+	}
+	return encode(w, format, entryCodec, recs)
+}
+
+func encode[R record](w io.Writer, format Format, c codec[R], recs []R) error {
+	if format == CSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(c.columns); err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if err := cw.Write(r.row()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func decode[R record](r io.Reader, format Format, c codec[R]) ([]R, error) {
+	var out []R
+	if format == CSV {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = len(c.columns)
+		rows, err := cr.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			if i == 0 {
+				continue // header
+			}
+			rec, err := c.parse(row)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			out = append(out, rec)
+		}
+		return out, nil
+	}
+	dec := json.NewDecoder(r)
+	for {
+		var rec R
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			return out, nil
+		} else if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+}
+
+// readFile decodes a partition file; a missing file has no records.
+func readFile[R record](path string, format Format, c codec[R]) ([]R, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	recs, err := decode(f, format, c)
+	if err != nil {
+		return nil, fmt.Errorf("file: reading %s: %w", path, err)
+	}
+	return recs, nil
+}
+
+// writeFile replaces a partition file atomically by renaming a temporary
+// file over it. No records removes the file.
+func writeFile[R record](path string, format Format, c codec[R], recs []R) error {
+	if len(recs) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := encode(tmp, format, c, recs); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// rowParser accumulates the first error while parsing CSV fields.
+type rowParser struct{ err error }
+
+func (p *rowParser) int(s string) int64 {
+	if p.err != nil {
+		return 0
+	}
+	var v int64
+	v, p.err = strconv.ParseInt(s, 10, 64)
+	return v
+}
+
+func (p *rowParser) optInt(s string) *int64 {
+	if s == "" {
+		return nil
+	}
+	v := p.int(s)
+	return &v
+}
+
+func (p *rowParser) bool(s string) bool {
+	if p.err != nil {
+		return false
+	}
+	var v bool
+	v, p.err = strconv.ParseBool(s)
+	return v
+}
+
+func (p *rowParser) time(s string) time.Time {
+	if p.err != nil {
+		return time.Time{}
+	}
+	var v time.Time
+	v, p.err = time.Parse(time.RFC3339Nano, s)
+	return v.UTC()
+}
+
+func (p *rowParser) optTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	v := p.time(s)
+	return &v
+}
+
+func formatInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
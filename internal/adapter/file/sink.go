@@ -0,0 +1,321 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// dayLayout names daily partition files, e.g. entries/2025-08-01.ndjson.
+const dayLayout = "2006-01-02"
+
+// Sink implements ports.Sink by writing entries and projects to a
+// directory of daily NDJSON or CSV files:
+//
+//	<dir>/entries/<YYYY-MM-DD>.<ext>  entries by UTC start day
+//	<dir>/projects/<YYYY-MM-DD>.<ext> project snapshot taken that UTC day
+//
+// Entries are merged by ID, so re-syncing a day rewrites its file instead
+// of appending duplicates. Other reference data is not written.
+type Sink struct {
+	dir    string
+	format Format
+	log    *slog.Logger
+
+	mu sync.Mutex
+	// index maps entry IDs to their partition day; loaded on first use so
+	// an entry whose start moves to another day leaves its old file.
+	index map[int64]string
+}
+
+// NewSink opens a file sink from a DSN of the form
+// /path/to/dir?format=csv. The format defaults to NDJSON and the
+// directory is created if needed.
+func NewSink(dsn string, log *slog.Logger) (*Sink, error) {
+	dir, query, _ := strings.Cut(dsn, "?")
+	if dir == "" {
+		return nil, errors.New("file: directory is required")
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	format, err := ParseFormat(params.Get("format"))
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range []string{"entries", "projects"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &Sink{dir: dir, format: format, log: log}, nil
+}
+
+func (s *Sink) path(kind, day string) string {
+	return filepath.Join(s.dir, kind, day+s.format.ext())
+}
+
+// days lists the partition days present for kind, in order.
+func (s *Sink) days(kind string) ([]string, error) {
+	names, err := filepath.Glob(filepath.Join(s.dir, kind, "*"+s.format.ext()))
+	if err != nil {
+		return nil, err
+	}
+	days := make([]string, 0, len(names))
+	for _, name := range names {
+		days = append(days, strings.TrimSuffix(filepath.Base(name), s.format.ext()))
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+func (s *Sink) loadIndex() error {
+	if s.index != nil {
+		return nil
+	}
+	days, err := s.days("entries")
+	if err != nil {
+		return err
+	}
+	index := make(map[int64]string)
+	for _, day := range days {
+		recs, err := readFile(s.path("entries", day), s.format, entryCodec)
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			index[r.ID] = day
+		}
+	}
+	s.index = index
+	return nil
+}
+
+// SyncEntries merges entries into their day partitions and rewrites each
+// affected file atomically.
+func (s *Sink) SyncEntries(ctx context.Context, entries []domain.TimeEntry) (domain.SyncCounts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadIndex(); err != nil {
+		return domain.SyncCounts{}, err
+	}
+
+	byDay := make(map[string][]entryRecord)
+	moved := make(map[string][]int64) // old day -> IDs that left it
+	for _, e := range entries {
+		r := newEntryRecord(e)
+		day := r.Start.Format(dayLayout)
+		byDay[day] = append(byDay[day], r)
+		if old, ok := s.index[r.ID]; ok && old != day {
+			moved[old] = append(moved[old], r.ID)
+		}
+	}
+
+	// Days that only lose entries are rewritten too.
+	for old := range moved {
+		if _, ok := byDay[old]; !ok {
+			byDay[old] = nil
+		}
+	}
+	var counts domain.SyncCounts
+	days := make([]string, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	for _, day := range days {
+		if err := ctx.Err(); err != nil {
+			return counts, err
+		}
+		recs, err := readFile(s.path("entries", day), s.format, entryCodec)
+		if err != nil {
+			return counts, err
+		}
+		current := make(map[int64]entryRecord, len(recs))
+		for _, r := range recs {
+			current[r.ID] = r
+		}
+		for _, id := range moved[day] {
+			delete(current, id)
+		}
+		for _, r := range byDay[day] {
+			prev, ok := current[r.ID]
+			switch {
+			case ok && sameRecord(prev, r):
+				counts.Unchanged++
+			case ok || s.index[r.ID] != "":
+				counts.Updated++
+			default:
+				counts.Inserted++
+			}
+			current[r.ID] = r
+		}
+		if err := writeFile(s.path("entries", day), s.format, entryCodec, sortedEntries(current)); err != nil {
+			return counts, err
+		}
+		for _, r := range byDay[day] {
+			s.index[r.ID] = day
+		}
+	}
+	s.logWritten("entries", counts)
+	return counts, nil
+}
+
+// MarkMissingDeleted sets deleted_at on stored entries that started in
+// [from, to) but are not in present.
+func (s *Sink) MarkMissingDeleted(ctx context.Context, from, to time.Time, workspaceID int64, present []int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keep := make(map[int64]bool, len(present))
+	for _, id := range present {
+		keep[id] = true
+	}
+	days, err := s.days("entries")
+	if err != nil {
+		return 0, err
+	}
+	first, last := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+	now := time.Now().UTC()
+	deleted := 0
+	for _, day := range days {
+		if day < first || day > last {
+			continue
+		}
+		recs, err := readFile(s.path("entries", day), s.format, entryCodec)
+		if err != nil {
+			return deleted, err
+		}
+		n := 0
+		for i, r := range recs {
+			if r.DeletedAt != nil || keep[r.ID] || r.Start.Before(from) || !r.Start.Before(to) {
+				continue
+			}
+			if workspaceID != 0 && (r.WorkspaceID == nil || *r.WorkspaceID != workspaceID) {
+				continue
+			}
+			recs[i].DeletedAt = &now
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		if err := writeFile(s.path("entries", day), s.format, entryCodec, recs); err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if deleted > 0 {
+		s.log.Info("file sink soft-deleted entries missing from toggl", slog.Int("count", deleted))
+	}
+	return deleted, nil
+}
+
+// SyncProjects writes today's project snapshot. Counts compare against the
+// most recent earlier snapshot.
+func (s *Sink) SyncProjects(ctx context.Context, projects []domain.Project) (domain.SyncCounts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	today := time.Now().UTC().Format(dayLayout)
+	days, err := s.days("projects")
+	if err != nil {
+		return domain.SyncCounts{}, err
+	}
+	prev := make(map[int64]projectRecord)
+	if len(days) > 0 {
+		recs, err := readFile(s.path("projects", days[len(days)-1]), s.format, projectCodec)
+		if err != nil {
+			return domain.SyncCounts{}, err
+		}
+		for _, r := range recs {
+			prev[r.ID] = r
+		}
+	}
+
+	var counts domain.SyncCounts
+	recs := make([]projectRecord, 0, len(projects))
+	for _, p := range projects {
+		r := newProjectRecord(p)
+		old, ok := prev[r.ID]
+		switch {
+		case !ok:
+			counts.Inserted++
+		case sameRecord(old, r):
+			counts.Unchanged++
+		default:
+			counts.Updated++
+		}
+		recs = append(recs, r)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].ID < recs[j].ID })
+	if err := writeFile(s.path("projects", today), s.format, projectCodec, recs); err != nil {
+		return domain.SyncCounts{}, err
+	}
+	s.logWritten("projects", counts)
+	return counts, nil
+}
+
+// SyncClients is a no-op; the file sink only stores entries and projects.
+func (s *Sink) SyncClients(ctx context.Context, clients []domain.Client) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncTags is a no-op; the file sink only stores entries and projects.
+func (s *Sink) SyncTags(ctx context.Context, tags []domain.Tag) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncTasks is a no-op; the file sink only stores entries and projects.
+func (s *Sink) SyncTasks(ctx context.Context, tasks []domain.Task) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncUsers is a no-op; the file sink only stores entries and projects.
+func (s *Sink) SyncUsers(ctx context.Context, users []domain.User) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncGroups is a no-op; the file sink only stores entries and projects.
+func (s *Sink) SyncGroups(ctx context.Context, groups []domain.Group) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+func (s *Sink) logWritten(entity string, counts domain.SyncCounts) {
+	s.log.Info("file sink wrote "+entity,
+		slog.Int("inserted", counts.Inserted),
+		slog.Int("updated", counts.Updated),
+		slog.Int("unchanged", counts.Unchanged),
+	)
+}
+
+// sameRecord compares two records by their encoded form.
+func sameRecord(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+// sortedEntries returns records ordered by start, then ID.
+func sortedEntries(m map[int64]entryRecord) []entryRecord {
+	out := make([]entryRecord, 0, len(m))
+	for _, r := range m {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"toggl-scraper/internal/adapter/sqlsink"
+)
+
+// dialect is the MySQL SQL of the shared sink.
+type dialect struct{}
+
+func (dialect) Name() string { return "mysql" }
+
+// MaxParams is MySQL's limit on placeholders per statement.
+func (dialect) MaxParams() int { return 65535 }
+
+func (dialect) Rebind(query string) string { return query }
+
+// UpsertQuery returns an INSERT ... ON DUPLICATE KEY UPDATE, which reports
+// 1 affected row per insert, 2 per update and 0 per unchanged row.
+func (dialect) UpsertQuery(u sqlsink.Upsert, n int) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + u.Table + " (" + strings.Join(u.Columns, ", ") + ") VALUES ")
+	b.WriteString(sqlsink.ValueGroups(n, len(u.Columns)))
+	b.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, col := range u.Update {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(col + "=VALUES(" + col + ")")
+	}
+	return b.String()
+}
+
+func (dialect) Returning() bool { return false }
+
+func (dialect) InsertIgnore(table string, columns []string, rows int) string {
+	return "INSERT IGNORE INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES " +
+		sqlsink.ValueGroups(rows, len(columns))
+}
+
+func (dialect) OnConflictUpdate(key []string, columns []string) string {
+	set := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = "  " + col + " = VALUES(" + col + ")"
+	}
+	return "ON DUPLICATE KEY UPDATE\n" + strings.Join(set, ",\n")
+}
+
+func (dialect) NullSafeEqual(a, b string) string { return a + " <=> " + b }
+
+func (dialect) JSONText(col string) string { return col }
+
+func (dialect) DayValue(day time.Time) any { return day }
+
+func (dialect) WeekStart(day string) string {
+	return "DATE_SUB(" + day + ", INTERVAL WEEKDAY(" + day + ") DAY)"
+}
+
+func (dialect) MonthStart(day string) string {
+	return "CAST(DATE_FORMAT(" + day + ", '%Y-%m-01') AS DATE)"
+}
+
+func (dialect) InsertID(ctx context.Context, db *sql.DB, query string, args ...any) (int64, error) {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
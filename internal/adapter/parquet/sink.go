@@ -0,0 +1,466 @@
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+
+	"toggl-scraper/internal/domain"
+)
+
+// partFile is the single data file in every partition directory.
+const partFile = "part-00000.parquet"
+
+// Sink implements ports.Sink by writing entries and projects to Parquet
+// files laid out Hive-style, readable by DuckDB with hive_partitioning:
+//
+//	<dir>/entries/year=YYYY/month=MM/day=DD/part-00000.parquet   by UTC start day
+//	<dir>/projects/year=YYYY/month=MM/day=DD/part-00000.parquet  snapshot of that UTC day
+//
+// Each partition is one file replaced atomically, so re-syncing a day
+// rewrites it instead of appending duplicates. Other reference data is not
+// written.
+type Sink struct {
+	dir string
+	log *slog.Logger
+
+	mu sync.Mutex
+	// index maps entry IDs to their partition day; loaded on first use so
+	// an entry whose start moves to another day leaves its old partition.
+	index map[int64]time.Time
+}
+
+// NewSink opens a Parquet sink rooted at dir, creating it if needed.
+func NewSink(dir string, log *slog.Logger) (*Sink, error) {
+	if dir == "" {
+		return nil, errors.New("parquet: directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Sink{dir: dir, log: log}, nil
+}
+
+// entryRow is the Parquet schema of a time entry.
+type entryRow struct {
+	ID          int64      `parquet:"id"`
+	WorkspaceID *int64     `parquet:"workspace_id"`
+	ProjectID   *int64     `parquet:"project_id"`
+	TaskID      *int64     `parquet:"task_id"`
+	UserID      *int64     `parquet:"user_id"`
+	Description string     `parquet:"description"`
+	Start       time.Time  `parquet:"start"`
+	Stop        *time.Time `parquet:"stop"`
+	DurationSec int64      `parquet:"duration_sec"`
+	Billable    bool       `parquet:"billable"`
+	Tags        []string   `parquet:"tags,list"`
+	At          *time.Time `parquet:"at"`
+	CreatedWith string     `parquet:"created_with"`
+	DeletedAt   *time.Time `parquet:"deleted_at"`
+	IsRunning   bool       `parquet:"is_running"`
+}
+
+func newEntryRow(e domain.TimeEntry) entryRow {
+	var at *time.Time
+	if !e.At.IsZero() {
+		at = utc(&e.At)
+	}
+	r := entryRow{
+		ID:          e.ID,
+		WorkspaceID: e.WorkspaceID,
+		ProjectID:   e.ProjectID,
+		TaskID:      e.TaskID,
+		UserID:      e.UserID,
+		Description: e.Description,
+		Start:       e.Start.UTC(),
+		Stop:        utc(e.Stop),
+		DurationSec: e.DurationSec,
+		Billable:    e.Billable,
+		Tags:        e.Tags,
+		At:          at,
+		CreatedWith: e.CreatedWith,
+		DeletedAt:   utc(e.DeletedAt),
+		IsRunning:   e.Running,
+	}
+	// Match rows read back from a file, so an unchanged entry compares
+	// equal whether its tags came as nil or empty.
+	r.normalize()
+	return r
+}
+
+// normalize makes rows read back from a file comparable with new ones.
+func (r *entryRow) normalize() {
+	r.Start = r.Start.UTC()
+	r.Stop = utc(r.Stop)
+	r.At = utc(r.At)
+	r.DeletedAt = utc(r.DeletedAt)
+	if len(r.Tags) == 0 {
+		r.Tags = nil
+	}
+}
+
+// projectRow is the Parquet schema of a project.
+type projectRow struct {
+	ID          int64     `parquet:"id"`
+	WorkspaceID int64     `parquet:"workspace_id"`
+	ClientID    *int64    `parquet:"client_id"`
+	Name        string    `parquet:"name"`
+	Active      bool      `parquet:"active"`
+	Private     bool      `parquet:"is_private"`
+	Color       string    `parquet:"color"`
+	At          time.Time `parquet:"at"`
+}
+
+func newProjectRow(p domain.Project) projectRow {
+	return projectRow{
+		ID:          p.ID,
+		WorkspaceID: p.WorkspaceID,
+		ClientID:    p.ClientID,
+		Name:        p.Name,
+		Active:      p.Active,
+		Private:     p.Private,
+		Color:       p.Color,
+		At:          p.At.UTC(),
+	}
+}
+
+// partition returns the file of kind's partition for day.
+func (s *Sink) partition(kind string, day time.Time) string {
+	return filepath.Join(s.dir, kind,
+		fmt.Sprintf("year=%04d", day.Year()),
+		fmt.Sprintf("month=%02d", int(day.Month())),
+		fmt.Sprintf("day=%02d", day.Day()),
+		partFile)
+}
+
+// partitions lists the days with a partition for kind, in order.
+func (s *Sink) partitions(kind string) ([]time.Time, error) {
+	names, err := filepath.Glob(filepath.Join(s.dir, kind, "year=*", "month=*", "day=*", partFile))
+	if err != nil {
+		return nil, err
+	}
+	days := make([]time.Time, 0, len(names))
+	for _, name := range names {
+		var y, m, d int
+		rel, _ := filepath.Rel(filepath.Join(s.dir, kind), name)
+		if _, err := fmt.Sscanf(filepath.ToSlash(rel), "year=%d/month=%d/day=%d/", &y, &m, &d); err != nil {
+			continue
+		}
+		days = append(days, time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC))
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+func readEntries(path string) ([]entryRow, error) {
+	rows, err := readFile[entryRow](path)
+	for i := range rows {
+		rows[i].normalize()
+	}
+	return rows, err
+}
+
+// readFile reads a partition; a missing partition has no rows.
+func readFile[T any](path string) ([]T, error) {
+	rows, err := pq.ReadFile[T](path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parquet: reading %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+// writeFile replaces a partition atomically: rows are written to a hidden
+// temporary file, which query engines ignore, and renamed over the old
+// file. No rows removes the partition.
+func writeFile[T any](path string, rows []T) error {
+	if len(rows) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".part-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := pq.Write(tmp, rows); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *Sink) loadIndex() error {
+	if s.index != nil {
+		return nil
+	}
+	days, err := s.partitions("entries")
+	if err != nil {
+		return err
+	}
+	index := make(map[int64]time.Time)
+	for _, day := range days {
+		rows, err := readEntries(s.partition("entries", day))
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			index[r.ID] = day
+		}
+	}
+	s.index = index
+	return nil
+}
+
+// SyncEntries merges entries into their day partitions and rewrites each
+// affected partition atomically.
+func (s *Sink) SyncEntries(ctx context.Context, entries []domain.TimeEntry) (domain.SyncCounts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadIndex(); err != nil {
+		return domain.SyncCounts{}, err
+	}
+
+	byDay := make(map[time.Time][]entryRow)
+	moved := make(map[time.Time][]int64) // old day -> IDs that left it
+	for _, e := range entries {
+		r := newEntryRow(e)
+		day := r.Start.Truncate(24 * time.Hour)
+		byDay[day] = append(byDay[day], r)
+		if old, ok := s.index[r.ID]; ok && !old.Equal(day) {
+			moved[old] = append(moved[old], r.ID)
+		}
+	}
+	// Days that only lose entries are rewritten too.
+	for old := range moved {
+		if _, ok := byDay[old]; !ok {
+			byDay[old] = nil
+		}
+	}
+	days := make([]time.Time, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	var counts domain.SyncCounts
+	for _, day := range days {
+		if err := ctx.Err(); err != nil {
+			return counts, err
+		}
+		path := s.partition("entries", day)
+		rows, err := readEntries(path)
+		if err != nil {
+			return counts, err
+		}
+		current := make(map[int64]entryRow, len(rows))
+		for _, r := range rows {
+			current[r.ID] = r
+		}
+		for _, id := range moved[day] {
+			delete(current, id)
+		}
+		for _, r := range byDay[day] {
+			prev, ok := current[r.ID]
+			_, known := s.index[r.ID]
+			switch {
+			case ok && sameRow(prev, r):
+				counts.Unchanged++
+			case ok || known:
+				counts.Updated++
+			default:
+				counts.Inserted++
+			}
+			current[r.ID] = r
+		}
+		if err := writeFile(path, sortedEntries(current)); err != nil {
+			return counts, err
+		}
+		for _, r := range byDay[day] {
+			s.index[r.ID] = day
+		}
+	}
+	s.logWritten("entries", counts)
+	return counts, nil
+}
+
+// MarkMissingDeleted sets deleted_at on stored entries that started in
+// [from, to) but are not in present.
+func (s *Sink) MarkMissingDeleted(ctx context.Context, from, to time.Time, workspaceID int64, present []int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keep := make(map[int64]bool, len(present))
+	for _, id := range present {
+		keep[id] = true
+	}
+	days, err := s.partitions("entries")
+	if err != nil {
+		return 0, err
+	}
+	first := from.UTC().Truncate(24 * time.Hour)
+	now := time.Now().UTC()
+	deleted := 0
+	for _, day := range days {
+		if day.Before(first) || !day.Before(to) {
+			continue
+		}
+		path := s.partition("entries", day)
+		rows, err := readEntries(path)
+		if err != nil {
+			return deleted, err
+		}
+		n := 0
+		for i, r := range rows {
+			if r.DeletedAt != nil || keep[r.ID] || r.Start.Before(from) || !r.Start.Before(to) {
+				continue
+			}
+			if workspaceID != 0 && (r.WorkspaceID == nil || *r.WorkspaceID != workspaceID) {
+				continue
+			}
+			rows[i].DeletedAt = &now
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		if err := writeFile(path, rows); err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if deleted > 0 {
+		s.log.Info("parquet sink soft-deleted entries missing from toggl", slog.Int("count", deleted))
+	}
+	return deleted, nil
+}
+
+// SyncProjects writes today's project snapshot partition. Counts compare
+// against the most recent earlier snapshot.
+func (s *Sink) SyncProjects(ctx context.Context, projects []domain.Project) (domain.SyncCounts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	days, err := s.partitions("projects")
+	if err != nil {
+		return domain.SyncCounts{}, err
+	}
+	prev := make(map[int64]projectRow)
+	if len(days) > 0 {
+		rows, err := readFile[projectRow](s.partition("projects", days[len(days)-1]))
+		if err != nil {
+			return domain.SyncCounts{}, err
+		}
+		for _, r := range rows {
+			r.At = r.At.UTC()
+			prev[r.ID] = r
+		}
+	}
+
+	var counts domain.SyncCounts
+	rows := make([]projectRow, 0, len(projects))
+	for _, p := range projects {
+		r := newProjectRow(p)
+		old, ok := prev[r.ID]
+		switch {
+		case !ok:
+			counts.Inserted++
+		case sameRow(old, r):
+			counts.Unchanged++
+		default:
+			counts.Updated++
+		}
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := writeFile(s.partition("projects", today), rows); err != nil {
+		return domain.SyncCounts{}, err
+	}
+	s.logWritten("projects", counts)
+	return counts, nil
+}
+
+// SyncClients is a no-op; the Parquet sink only stores entries and projects.
+func (s *Sink) SyncClients(ctx context.Context, clients []domain.Client) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncTags is a no-op; the Parquet sink only stores entries and projects.
+func (s *Sink) SyncTags(ctx context.Context, tags []domain.Tag) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncTasks is a no-op; the Parquet sink only stores entries and projects.
+func (s *Sink) SyncTasks(ctx context.Context, tasks []domain.Task) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncUsers is a no-op; the Parquet sink only stores entries and projects.
+func (s *Sink) SyncUsers(ctx context.Context, users []domain.User) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+// SyncGroups is a no-op; the Parquet sink only stores entries and projects.
+func (s *Sink) SyncGroups(ctx context.Context, groups []domain.Group) (domain.SyncCounts, error) {
+	return domain.SyncCounts{}, nil
+}
+
+func (s *Sink) logWritten(entity string, counts domain.SyncCounts) {
+	s.log.Info("parquet sink wrote "+entity,
+		slog.Int("inserted", counts.Inserted),
+		slog.Int("updated", counts.Updated),
+		slog.Int("unchanged", counts.Unchanged),
+	)
+}
+
+// sameRow compares two rows by their JSON encoding.
+func sameRow(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+// sortedEntries returns rows ordered by start, then ID.
+func sortedEntries(m map[int64]entryRow) []entryRow {
+	out := make([]entryRow, 0, len(m))
+	for _, r := range m {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"toggl-scraper/internal/adapter/sqlsink"
+)
+
+// dialect is the PostgreSQL SQL of the shared sink.
+type dialect struct{}
+
+func (dialect) Name() string { return "postgres" }
+
+// MaxParams is PostgreSQL's limit on parameters per statement.
+func (dialect) MaxParams() int { return 65535 }
+
+func (dialect) Rebind(query string) string { return sqlsink.Numbered(query) }
+
+// UpsertQuery returns an INSERT ... ON CONFLICT (id) DO UPDATE that only
+// fires for rows whose values differ, so rows missing from the RETURNING
+// result were unchanged.
+func (dialect) UpsertQuery(u sqlsink.Upsert, n int) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + u.Table + " AS t (" + strings.Join(u.Columns, ", ") + ") VALUES ")
+	b.WriteString(sqlsink.ValueGroups(n, len(u.Columns)))
+	b.WriteString(" ON CONFLICT (id) DO UPDATE SET ")
+	current := make([]string, len(u.Update))
+	excluded := make([]string, len(u.Update))
+	for i, col := range u.Update {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(col + " = EXCLUDED." + col)
+		current[i] = "t." + col
+		excluded[i] = "EXCLUDED." + col
+	}
+	b.WriteString(" WHERE (" + strings.Join(current, ", ") + ") IS DISTINCT FROM (" + strings.Join(excluded, ", ") + ")")
+	b.WriteString(" RETURNING id")
+	return b.String()
+}
+
+func (dialect) Returning() bool { return true }
+
+func (dialect) InsertIgnore(table string, columns []string, rows int) string {
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES " +
+		sqlsink.ValueGroups(rows, len(columns)) + " ON CONFLICT DO NOTHING"
+}
+
+func (dialect) OnConflictUpdate(key []string, columns []string) string {
+	set := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = "  " + col + " = EXCLUDED." + col
+	}
+	return "ON CONFLICT (" + strings.Join(key, ", ") + ") DO UPDATE SET\n" + strings.Join(set, ",\n")
+}
+
+func (dialect) NullSafeEqual(a, b string) string { return a + " IS NOT DISTINCT FROM " + b }
+
+// JSONText casts jsonb columns so they scan into strings.
+func (dialect) JSONText(col string) string { return col + "::text" }
+
+func (dialect) DayValue(day time.Time) any { return day }
+
+func (dialect) WeekStart(day string) string { return "CAST(date_trunc('week', " + day + ") AS DATE)" }
+
+func (dialect) MonthStart(day string) string { return "CAST(date_trunc('month', " + day + ") AS DATE)" }
+
+func (dialect) InsertID(ctx context.Context, db *sql.DB, query string, args ...any) (int64, error) {
+	var id int64
+	err := db.QueryRowContext(ctx, query+"\nRETURNING id", args...).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"toggl-scraper/internal/adapter/sqlsink"
+)
+
+// dialect is the SQLite SQL of the shared sink.
+type dialect struct{}
+
+func (dialect) Name() string { return "sqlite" }
+
+// MaxParams is SQLite's default limit on parameters per statement.
+func (dialect) MaxParams() int { return 32766 }
+
+func (dialect) Rebind(query string) string { return query }
+
+// UpsertQuery returns an INSERT ... ON CONFLICT (id) DO UPDATE that only
+// fires for rows whose values differ, so rows missing from the RETURNING
+// result were unchanged.
+func (dialect) UpsertQuery(u sqlsink.Upsert, n int) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + u.Table + " (" + strings.Join(u.Columns, ", ") + ") VALUES ")
+	b.WriteString(sqlsink.ValueGroups(n, len(u.Columns)))
+	b.WriteString(" ON CONFLICT (id) DO UPDATE SET ")
+	current := make([]string, len(u.Update))
+	excluded := make([]string, len(u.Update))
+	for i, col := range u.Update {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(col + " = excluded." + col)
+		current[i] = u.Table + "." + col
+		excluded[i] = "excluded." + col
+	}
+	b.WriteString(" WHERE (" + strings.Join(current, ", ") + ") IS NOT (" + strings.Join(excluded, ", ") + ")")
+	b.WriteString(" RETURNING id")
+	return b.String()
+}
+
+func (dialect) Returning() bool { return true }
+
+func (dialect) InsertIgnore(table string, columns []string, rows int) string {
+	return "INSERT OR IGNORE INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES " +
+		sqlsink.ValueGroups(rows, len(columns))
+}
+
+func (dialect) OnConflictUpdate(key []string, columns []string) string {
+	set := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = "  " + col + " = excluded." + col
+	}
+	return "ON CONFLICT (" + strings.Join(key, ", ") + ") DO UPDATE SET\n" + strings.Join(set, ",\n")
+}
+
+func (dialect) NullSafeEqual(a, b string) string { return a + " IS " + b }
+
+func (dialect) JSONText(col string) string { return col }
+
+// DayValue stores days as "YYYY-MM-DD" text, which date() understands.
+func (dialect) DayValue(day time.Time) any { return day.Format(time.DateOnly) }
+
+func (dialect) WeekStart(day string) string { return "date(" + day + ", '-6 days', 'weekday 1')" }
+
+func (dialect) MonthStart(day string) string { return "date(" + day + ", 'start of month')" }
+
+func (dialect) InsertID(ctx context.Context, db *sql.DB, query string, args ...any) (int64, error) {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"toggl-scraper/internal/adapter/sqlsink"
+)
+
+// DefaultBatchSize is the number of rows per multi-row upsert.
+const DefaultBatchSize = sqlsink.DefaultBatchSize
+
+// Client implements ports.Sink by writing to a SQLite database file. The
+// layout matches the MySQL sink.
+type Client struct {
+	*sqlsink.Store
+}
+
+// NewClient opens the SQLite database file at path, e.g. /data/toggl.db.
+// batchSize is the number of rows per multi-row upsert; <= 0 selects
+// DefaultBatchSize. loc sets the midnight at which entries are split into
+// daily segments; nil selects UTC.
+func NewClient(ctx context.Context, path string, batchSize int, loc *time.Location, log *slog.Logger) (*Client, error) {
+	if path == "" {
+		return nil, errors.New("sqlite: database path is required")
+	}
+	// WAL lets readers such as Metabase query while a sync writes. Times
+	// are stored as "YYYY-MM-DD HH:MM:SS+00:00" text, which SQLite's date
+	// functions understand and which sorts chronologically in UTC.
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY
+	// between our own transactions.
+	db.SetMaxOpenConns(1)
+
+	c, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(c); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Client{Store: sqlsink.New(db, dialect{}, batchSize, loc, log)}, nil
+}
@@ -0,0 +1,142 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// Upsert describes a multi-row upsert into a table keyed by an `id`
+// column, which must be the first column.
+type Upsert struct {
+	Table   string
+	Columns []string
+	Update  []string // columns overwritten when the id exists
+}
+
+// afterBatchFunc runs inside a batch transaction after its rows were
+// upserted; rows [lo, hi) of the caller's input belong to the batch.
+type afterBatchFunc func(ctx context.Context, tx *sql.Tx, lo, hi int) error
+
+// upsertRows writes rows in batches of s.batchSize. Each batch commits in
+// its own transaction, so a failure keeps earlier batches and the returned
+// counts cover everything committed so far.
+func (s *Store) upsertRows(ctx context.Context, u Upsert, rows [][]any, after afterBatchFunc) (domain.SyncCounts, error) {
+	size := s.batchSize
+	if limit := s.d.MaxParams() / len(u.Columns); size > limit {
+		size = limit
+	}
+	var counts domain.SyncCounts
+	for lo := 0; lo < len(rows); lo += size {
+		hi := min(lo+size, len(rows))
+		batch, err := s.upsertBatch(ctx, u, rows, lo, hi, after)
+		if err != nil {
+			return counts, err
+		}
+		counts.Add(batch)
+	}
+	return counts, nil
+}
+
+func (s *Store) upsertBatch(ctx context.Context, u Upsert, rows [][]any, lo, hi int, after afterBatchFunc) (domain.SyncCounts, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return domain.SyncCounts{}, err
+	}
+	n := hi - lo
+	ids := make([]any, 0, n)
+	args := make([]any, 0, n*len(u.Columns))
+	for _, r := range rows[lo:hi] {
+		ids = append(ids, r[0])
+		args = append(args, r...)
+	}
+
+	// Upserts report either the rows they wrote or MySQL's sum of affected
+	// rows (1 per insert, 2 per update, 0 per unchanged row); neither tells
+	// inserts from updates, so count existing rows first.
+	var existing int
+	if err := tx.QueryRowContext(ctx,
+		s.d.Rebind("SELECT COUNT(*) FROM "+u.Table+" WHERE id IN ("+Placeholders(n)+")"), ids...,
+	).Scan(&existing); err != nil {
+		tx.Rollback()
+		return domain.SyncCounts{}, err
+	}
+	updated, err := s.execUpsert(ctx, tx, u, n, n-existing, args)
+	if err != nil {
+		tx.Rollback()
+		return domain.SyncCounts{}, err
+	}
+	if after != nil {
+		if err := after(ctx, tx, lo, hi); err != nil {
+			tx.Rollback()
+			return domain.SyncCounts{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.SyncCounts{}, err
+	}
+	return domain.SyncCounts{
+		Inserted:  n - existing,
+		Updated:   updated,
+		Unchanged: existing - updated,
+	}, nil
+}
+
+// execUpsert runs the upsert of n rows, inserted of which are new, and
+// returns the number of existing rows it changed.
+func (s *Store) execUpsert(ctx context.Context, tx *sql.Tx, u Upsert, n, inserted int, args []any) (int, error) {
+	q := s.d.Rebind(s.d.UpsertQuery(u, n))
+	if !s.d.Returning() {
+		res, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		// Without RETURNING (MySQL), an insert counts 1 and a changed row
+		// 2; unchanged rows count 0 as long as clientFoundRows is off.
+		return (int(affected) - inserted) / 2, nil
+	}
+	res, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	written := 0
+	for res.Next() {
+		written++
+	}
+	res.Close()
+	if err := res.Err(); err != nil {
+		return 0, err
+	}
+	return written - inserted, nil
+}
+
+// nullInt64 converts an optional ID to a driver value.
+func nullInt64(p *int64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// nullTime converts an optional timestamp to a UTC driver value.
+func nullTime(p *time.Time) any {
+	if p == nil {
+		return nil
+	}
+	return p.UTC()
+}
+
+// zeroNullTime converts a timestamp to a UTC driver value, with the zero
+// time as NULL.
+func zeroNullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,88 @@
+package sqlsink
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// deleteBatchSize bounds the number of IDs per soft-delete UPDATE.
+const deleteBatchSize = 500
+
+// MarkMissingDeleted soft-deletes live entries that started in [from, to)
+// but whose IDs are not in present. It must only be called with the
+// complete result of a window fetch. A non-zero workspaceID restricts the
+// scope to that workspace. It returns the number of rows soft-deleted.
+func (s *Store) MarkMissingDeleted(ctx context.Context, from, to time.Time, workspaceID int64, present []int64) (int, error) {
+	q := "SELECT id FROM toggl_time_entries WHERE start >= ? AND start < ? AND deleted_at IS NULL"
+	args := []any{from.UTC(), to.UTC()}
+	if workspaceID != 0 {
+		q += " AND workspace_id = ?"
+		args = append(args, workspaceID)
+	}
+	rows, err := s.db.QueryContext(ctx, s.d.Rebind(q), args...)
+	if err != nil {
+		return 0, err
+	}
+	keep := make(map[int64]bool, len(present))
+	for _, id := range present {
+		keep[id] = true
+	}
+	var missing []any
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !keep[id] {
+			missing = append(missing, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UTC()
+	for i := 0; i < len(missing); i += deleteBatchSize {
+		batch := missing[i:min(i+deleteBatchSize, len(missing))]
+		if err := s.softDelete(ctx, now, batch); err != nil {
+			return 0, err
+		}
+	}
+	s.log.Info(s.d.Name()+" sink soft-deleted entries missing from toggl", slog.Int("count", len(missing)))
+	return len(missing), nil
+}
+
+// softDelete soft-deletes one batch of IDs, drops their daily segments,
+// refreshes the rollups of those days and closes their open versions in
+// the same transaction.
+func (s *Store) softDelete(ctx context.Context, now time.Time, ids []any) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	q := "UPDATE toggl_time_entries SET deleted_at = ? WHERE deleted_at IS NULL AND id IN (" + Placeholders(len(ids)) + ")"
+	if _, err := tx.ExecContext(ctx, s.d.Rebind(q), append([]any{now}, ids...)...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	days, err := s.deleteDaySegments(ctx, tx, ids)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.refreshRollups(ctx, tx, days); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.recordVersions(ctx, tx, ids, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,91 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"toggl-scraper/internal/domain"
+)
+
+// SaveJob inserts or replaces a sync_jobs row.
+func (s *Store) SaveJob(ctx context.Context, job domain.Job) error {
+	counts, err := json.Marshal(job.Counts)
+	if err != nil {
+		return err
+	}
+	sinks, err := json.Marshal(job.Sinks)
+	if err != nil {
+		return err
+	}
+	var errText, runID any
+	if job.Error != "" {
+		errText = job.Error
+	}
+	if job.RunID != 0 {
+		runID = job.RunID
+	}
+	q := s.d.Rebind(`
+INSERT INTO sync_jobs
+  (id, state, mode, window_from, window_to, run_id, counts, sinks, error, created_at, started_at, finished_at)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+` + s.d.OnConflictUpdate([]string{"id"}, []string{
+		"state", "mode", "window_from", "window_to", "run_id", "counts", "sinks", "error", "created_at", "started_at", "finished_at",
+	}))
+	_, err = s.db.ExecContext(ctx, q,
+		job.ID,
+		string(job.State),
+		job.Mode,
+		zeroNullTime(job.From),
+		zeroNullTime(job.To),
+		runID,
+		string(counts),
+		string(sinks),
+		errText,
+		job.CreatedAt.UTC(),
+		zeroNullTime(job.StartedAt),
+		zeroNullTime(job.FinishedAt),
+	)
+	return err
+}
+
+// LoadJob reads a sync_jobs row by ID.
+func (s *Store) LoadJob(ctx context.Context, id string) (domain.Job, bool, error) {
+	var (
+		job                         domain.Job
+		state                       string
+		from, to, started, finished sql.NullTime
+		runID                       sql.NullInt64
+		counts, sinks, errText      sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, s.d.Rebind(`
+SELECT id, state, mode, window_from, window_to, run_id, `+s.d.JSONText("counts")+`, `+s.d.JSONText("sinks")+`, error, created_at, started_at, finished_at
+FROM sync_jobs WHERE id = ?`), id).Scan(
+		&job.ID, &state, &job.Mode, &from, &to, &runID, &counts, &sinks, &errText, &job.CreatedAt, &started, &finished,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Job{}, false, nil
+	}
+	if err != nil {
+		return domain.Job{}, false, err
+	}
+	job.State = domain.JobState(state)
+	job.From, job.To = from.Time.UTC(), to.Time.UTC()
+	job.RunID = runID.Int64
+	job.Error = errText.String
+	job.CreatedAt = job.CreatedAt.UTC()
+	job.StartedAt, job.FinishedAt = started.Time.UTC(), finished.Time.UTC()
+	if counts.Valid {
+		if err := json.Unmarshal([]byte(counts.String), &job.Counts); err != nil {
+			return domain.Job{}, false, err
+		}
+	}
+	if sinks.Valid {
+		if err := json.Unmarshal([]byte(sinks.String), &job.Sinks); err != nil {
+			return domain.Job{}, false, err
+		}
+	}
+	return job, true, nil
+}
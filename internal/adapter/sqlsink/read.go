@@ -0,0 +1,132 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// tagLookupBatchSize bounds the number of entry IDs per tag lookup.
+const tagLookupBatchSize = 500
+
+// ListEntries returns live entries that started in [from, to), ordered by
+// start time.
+func (s *Store) ListEntries(ctx context.Context, from, to time.Time) ([]domain.TimeEntry, error) {
+	entries, err := s.queryEntries(ctx, `
+SELECT id, description, project_id, task_id, workspace_id, `+s.d.JSONText("tags")+`, start, stop, duration_sec,
+  billable, user_id, at, created_with, is_running
+FROM toggl_time_entries_active
+WHERE start >= ? AND start < ?
+ORDER BY start, id`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return entries, s.loadTagIDs(ctx, entries)
+}
+
+// ListRunningEntries returns live entries stored as running timers.
+func (s *Store) ListRunningEntries(ctx context.Context) ([]domain.TimeEntry, error) {
+	entries, err := s.queryEntries(ctx, `
+SELECT id, description, project_id, task_id, workspace_id, `+s.d.JSONText("tags")+`, start, stop, duration_sec,
+  billable, user_id, at, created_with, is_running
+FROM toggl_time_entries_active
+WHERE is_running
+ORDER BY start, id`)
+	if err != nil {
+		return nil, err
+	}
+	return entries, s.loadTagIDs(ctx, entries)
+}
+
+// loadTagIDs fills TagIDs of entries from toggl_time_entry_tags, in tag ID
+// order, so entries read back keep their tag membership when re-synced.
+func (s *Store) loadTagIDs(ctx context.Context, entries []domain.TimeEntry) error {
+	index := make(map[int64]*domain.TimeEntry, len(entries))
+	for i := range entries {
+		index[entries[i].ID] = &entries[i]
+	}
+	ids := entryIDs(entries)
+	for i := 0; i < len(ids); i += tagLookupBatchSize {
+		batch := ids[i:min(i+tagLookupBatchSize, len(ids))]
+		rows, err := s.db.QueryContext(ctx, s.d.Rebind(
+			"SELECT entry_id, tag_id FROM toggl_time_entry_tags WHERE entry_id IN ("+Placeholders(len(batch))+") ORDER BY entry_id, tag_id"),
+			batch...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var entryID, tagID int64
+			if err := rows.Scan(&entryID, &tagID); err != nil {
+				rows.Close()
+				return err
+			}
+			e := index[entryID]
+			e.TagIDs = append(e.TagIDs, tagID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryEntries runs a query selecting the columns scanEntry reads.
+func (s *Store) queryEntries(ctx context.Context, q string, args ...any) ([]domain.TimeEntry, error) {
+	rows, err := s.db.QueryContext(ctx, s.d.Rebind(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.TimeEntry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func scanEntry(rows *sql.Rows) (domain.TimeEntry, error) {
+	var (
+		e                             domain.TimeEntry
+		description, tags, createdBy  sql.NullString
+		projectID, taskID, wsID, user sql.NullInt64
+		stop, at                      sql.NullTime
+	)
+	if err := rows.Scan(&e.ID, &description, &projectID, &taskID, &wsID, &tags, &e.Start, &stop, &e.DurationSec,
+		&e.Billable, &user, &at, &createdBy, &e.Running); err != nil {
+		return e, err
+	}
+	e.Description = description.String
+	e.ProjectID = int64Ptr(projectID)
+	e.TaskID = int64Ptr(taskID)
+	e.WorkspaceID = int64Ptr(wsID)
+	e.UserID = int64Ptr(user)
+	if tags.Valid {
+		_ = json.Unmarshal([]byte(tags.String), &e.Tags)
+	}
+	e.Start = e.Start.UTC()
+	if stop.Valid {
+		t := stop.Time.UTC()
+		e.Stop = &t
+	}
+	if at.Valid {
+		e.At = at.Time.UTC()
+	}
+	e.CreatedWith = createdBy.String
+	return e, nil
+}
+
+func int64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
@@ -0,0 +1,124 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// rollupChunk bounds the number of periods refreshed per statement.
+const rollupChunk = 200
+
+// rollup is a materialized toggl_rollup_* table, keyed by a period of
+// toggl_daily_entry_segments.day and the project.
+type rollup struct {
+	table, key string
+	// period returns the SQL period of the DATE expression day.
+	period func(d Dialect, day string) string
+	// start and next return the first day of the period holding day and
+	// of the period after it.
+	start, next func(day time.Time) time.Time
+}
+
+var rollups = []rollup{
+	{
+		table:  "toggl_rollup_daily",
+		key:    "day",
+		period: func(_ Dialect, day string) string { return day },
+		start:  func(day time.Time) time.Time { return day },
+		next:   func(day time.Time) time.Time { return day.AddDate(0, 0, 1) },
+	},
+	{
+		table:  "toggl_rollup_weekly",
+		key:    "week_start",
+		period: Dialect.WeekStart,
+		start:  func(day time.Time) time.Time { return day.AddDate(0, 0, -(int(day.Weekday())+6)%7) },
+		next:   func(day time.Time) time.Time { return day.AddDate(0, 0, 7) },
+	},
+	{
+		table:  "toggl_rollup_monthly",
+		key:    "month_start",
+		period: Dialect.MonthStart,
+		start:  func(day time.Time) time.Time { return day.AddDate(0, 0, 1-day.Day()) },
+		next:   func(day time.Time) time.Time { return day.AddDate(0, 1, 0) },
+	},
+}
+
+// daySet collects the segment days a batch touched, as midnight UTC.
+type daySet map[time.Time]bool
+
+// refreshRollups recomputes the rollup rows of every period holding one of
+// days from the segments of live entries. It runs in the batch's
+// transaction, so reports never see rollups out of step with segments.
+func (s *Store) refreshRollups(ctx context.Context, tx *sql.Tx, days daySet) error {
+	if len(days) == 0 {
+		return nil
+	}
+	for _, r := range rollups {
+		seen := make(map[time.Time]bool)
+		var periods []time.Time
+		for day := range days {
+			if p := r.start(day); !seen[p] {
+				seen[p] = true
+				periods = append(periods, p)
+			}
+		}
+		for i := 0; i < len(periods); i += rollupChunk {
+			if err := s.refreshPeriods(ctx, tx, r, periods[i:min(i+rollupChunk, len(periods))]); err != nil {
+				return fmt.Errorf("refresh %s: %w", r.table, err)
+			}
+		}
+	}
+	s.log.Debug(s.d.Name()+" sink refreshed rollups", slog.Int("days", len(days)))
+	return nil
+}
+
+// refreshPeriods replaces the rows of r for periods.
+func (s *Store) refreshPeriods(ctx context.Context, tx *sql.Tx, r rollup, periods []time.Time) error {
+	keys := make([]any, 0, len(periods))
+	ranges := make([]string, 0, len(periods))
+	bounds := make([]any, 0, 2*len(periods))
+	for _, p := range periods {
+		keys = append(keys, s.d.DayValue(p))
+		ranges = append(ranges, "(s.day >= ? AND s.day < ?)")
+		bounds = append(bounds, s.d.DayValue(p), s.d.DayValue(r.next(p)))
+	}
+	if _, err := tx.ExecContext(ctx,
+		s.d.Rebind("DELETE FROM "+r.table+" WHERE "+r.key+" IN ("+Placeholders(len(keys))+")"), keys...,
+	); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, s.d.Rebind(`
+INSERT INTO `+r.table+` (`+r.key+`, project_id, seconds, entries)
+SELECT `+r.period(s.d, "s.day")+`, COALESCE(e.project_id, 0), SUM(s.seconds), COUNT(DISTINCT s.entry_id)
+FROM toggl_daily_entry_segments s
+JOIN toggl_time_entries e ON e.id = s.entry_id AND e.deleted_at IS NULL
+WHERE `+strings.Join(ranges, " OR ")+`
+GROUP BY 1, 2`), bounds...)
+	return err
+}
+
+// dayValue scans a DATE column, which drivers return as a time or as
+// "YYYY-MM-DD" text.
+type dayValue time.Time
+
+func (d *dayValue) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = dayValue(time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC))
+		return nil
+	case []byte:
+		return d.Scan(string(v))
+	case string:
+		t, err := time.Parse(time.DateOnly, v[:min(len(v), len(time.DateOnly))])
+		if err != nil {
+			return err
+		}
+		*d = dayValue(t)
+		return nil
+	}
+	return fmt.Errorf("sqlsink: cannot scan %T into a day", src)
+}
@@ -0,0 +1,65 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+
+	"toggl-scraper/internal/domain"
+)
+
+// StartRun inserts a sync_runs row for a run in progress and returns its ID.
+func (s *Store) StartRun(ctx context.Context, run domain.SyncRun) (int64, error) {
+	return s.d.InsertID(ctx, s.db, s.d.Rebind(`
+INSERT INTO sync_runs
+  (trigger_source, mode, window_from, window_to, started_at, status)
+VALUES
+  (?, ?, ?, ?, ?, ?)`),
+		string(run.Trigger),
+		run.Mode,
+		run.From.UTC(),
+		run.To.UTC(),
+		run.StartedAt.UTC(),
+		string(run.Status),
+	)
+}
+
+// FinishRun stores the final status, error and per-entity counts of a run.
+func (s *Store) FinishRun(ctx context.Context, run domain.SyncRun) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	var errText any
+	if run.Error != "" {
+		errText = run.Error
+	}
+	if _, err := tx.ExecContext(ctx, s.d.Rebind(`
+UPDATE sync_runs
+SET window_from = ?, window_to = ?, finished_at = ?, status = ?, error = ?
+WHERE id = ?`),
+		run.From.UTC(),
+		run.To.UTC(),
+		run.FinishedAt.UTC(),
+		string(run.Status),
+		errText,
+		run.ID,
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+	countsUpsert := s.d.Rebind(`
+INSERT INTO sync_run_counts
+  (run_id, entity, fetched, inserted, updated, unchanged, deleted)
+VALUES
+  (?, ?, ?, ?, ?, ?, ?)
+` + s.d.OnConflictUpdate([]string{"run_id", "entity"}, []string{"fetched", "inserted", "updated", "unchanged", "deleted"}))
+	for entity, n := range run.Counts {
+		if _, err := tx.ExecContext(ctx, countsUpsert,
+			run.ID, entity, n.Fetched, n.Inserted, n.Updated, n.Unchanged, n.Deleted,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,75 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// segmentBatchSize bounds the number of rows per segment INSERT; a long
+// entry can span many days.
+const segmentBatchSize = 1000
+
+// replaceDaySegments recomputes toggl_daily_entry_segments for entries,
+// split at midnight in s.loc. Deleted and running entries lose their rows.
+// It returns the days whose segments changed, old and new.
+func (s *Store) replaceDaySegments(ctx context.Context, tx *sql.Tx, entries []domain.TimeEntry) (daySet, error) {
+	ids := make([]any, 0, len(entries))
+	var segments []domain.DaySegment
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+		segments = append(segments, e.DaySegments(s.loc)...)
+	}
+	days, err := s.deleteDaySegments(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segments {
+		days[seg.Day] = true
+	}
+	for i := 0; i < len(segments); i += segmentBatchSize {
+		batch := segments[i:min(i+segmentBatchSize, len(segments))]
+		args := make([]any, 0, len(batch)*3)
+		for _, seg := range batch {
+			args = append(args, seg.EntryID, s.d.DayValue(seg.Day), seg.Seconds)
+		}
+		if _, err := tx.ExecContext(ctx,
+			s.d.Rebind("INSERT INTO toggl_daily_entry_segments (entry_id, day, seconds) VALUES "+ValueGroups(len(batch), 3)), args...,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return days, nil
+}
+
+// deleteDaySegments removes the segments of the given entry IDs and
+// returns the days they covered.
+func (s *Store) deleteDaySegments(ctx context.Context, tx *sql.Tx, ids []any) (daySet, error) {
+	days := make(daySet)
+	if len(ids) == 0 {
+		return days, nil
+	}
+	in := "entry_id IN (" + Placeholders(len(ids)) + ")"
+	rows, err := tx.QueryContext(ctx, s.d.Rebind("SELECT DISTINCT day FROM toggl_daily_entry_segments WHERE "+in), ids...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var day dayValue
+		if err := rows.Scan(&day); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		days[time.Time(day)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, s.d.Rebind("DELETE FROM toggl_daily_entry_segments WHERE "+in), ids...); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
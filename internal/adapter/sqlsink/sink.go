@@ -0,0 +1,266 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+var entriesUpsert = Upsert{
+	Table: "toggl_time_entries",
+	Columns: []string{
+		"id", "description", "project_id", "task_id", "workspace_id", "tags", "start", "stop", "duration_sec",
+		"billable", "user_id", "at", "server_deleted_at", "created_with", "deleted_at", "is_running",
+	},
+	Update: []string{
+		"description", "project_id", "task_id", "workspace_id", "tags", "start", "stop", "duration_sec",
+		"billable", "user_id", "at", "server_deleted_at", "created_with", "deleted_at", "is_running",
+	},
+}
+
+// SyncEntries upserts entries in batches and rebuilds their rows in
+// toggl_time_entry_tags and toggl_daily_entry_segments, recording a new
+// version in toggl_time_entry_versions for each changed entry. The rollup
+// tables are refreshed afterwards.
+func (s *Store) SyncEntries(ctx context.Context, entries []domain.TimeEntry) (domain.SyncCounts, error) {
+	if len(entries) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(entries))
+	for _, e := range entries {
+		// Tags are stored as a JSON array, empty rather than null.
+		tags := e.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		tagsJSON, _ := json.Marshal(tags)
+		var at any
+		if !e.At.IsZero() {
+			at = e.At.UTC()
+		}
+		rows = append(rows, []any{
+			e.ID,
+			e.Description,
+			nullInt64(e.ProjectID),
+			nullInt64(e.TaskID),
+			nullInt64(e.WorkspaceID),
+			string(tagsJSON),
+			e.Start.UTC(),
+			nullTime(e.Stop),
+			e.DurationSec,
+			e.Billable,
+			nullInt64(e.UserID),
+			at,
+			nullTime(e.DeletedAt),
+			e.CreatedWith,
+			// An entry returned without server_deleted_at is live, which
+			// also revives rows previously soft-deleted by window diffing.
+			nullTime(e.DeletedAt),
+			e.Running,
+		})
+	}
+	now := time.Now().UTC()
+	counts, err := s.upsertRows(ctx, entriesUpsert, rows, func(ctx context.Context, tx *sql.Tx, lo, hi int) error {
+		batch := entries[lo:hi]
+		if err := s.replaceEntryTags(ctx, tx, batch); err != nil {
+			return err
+		}
+		days, err := s.replaceDaySegments(ctx, tx, batch)
+		if err != nil {
+			return err
+		}
+		if err := s.refreshRollups(ctx, tx, days); err != nil {
+			return err
+		}
+		return s.recordVersions(ctx, tx, entryIDs(batch), now)
+	})
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("entries", counts)
+	return counts, nil
+}
+
+// replaceEntryTags rebuilds tag membership for entries so removed tags
+// disappear from the join table.
+func (s *Store) replaceEntryTags(ctx context.Context, tx *sql.Tx, entries []domain.TimeEntry) error {
+	ids := make([]any, 0, len(entries))
+	var pairs []any
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+		for _, tagID := range e.TagIDs {
+			pairs = append(pairs, e.ID, tagID)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		s.d.Rebind("DELETE FROM toggl_time_entry_tags WHERE entry_id IN ("+Placeholders(len(ids))+")"), ids...,
+	); err != nil {
+		return err
+	}
+	return s.insertPairs(ctx, tx, "toggl_time_entry_tags", []string{"entry_id", "tag_id"}, pairs)
+}
+
+// insertPairs inserts flattened two-column rows into a join table,
+// ignoring duplicates, in statements within the dialect's parameter limit.
+func (s *Store) insertPairs(ctx context.Context, tx *sql.Tx, table string, columns []string, pairs []any) error {
+	chunk := s.d.MaxParams() / 2 * 2
+	for i := 0; i < len(pairs); i += chunk {
+		batch := pairs[i:min(i+chunk, len(pairs))]
+		if _, err := tx.ExecContext(ctx,
+			s.d.Rebind(s.d.InsertIgnore(table, columns, len(batch)/2)), batch...,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncProjects upserts projects.
+func (s *Store) SyncProjects(ctx context.Context, projects []domain.Project) (domain.SyncCounts, error) {
+	if len(projects) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(projects))
+	for _, p := range projects {
+		rows = append(rows, []any{p.ID, p.WorkspaceID, p.Name, p.Active, p.Private, p.Color, nullInt64(p.ClientID), p.At.UTC()})
+	}
+	counts, err := s.upsertRows(ctx, Upsert{
+		Table:   "toggl_projects",
+		Columns: []string{"id", "workspace_id", "name", "active", "is_private", "color", "client_id", "at"},
+		Update:  []string{"workspace_id", "name", "active", "is_private", "color", "client_id", "at"},
+	}, rows, nil)
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("projects", counts)
+	return counts, nil
+}
+
+// SyncClients upserts clients.
+func (s *Store) SyncClients(ctx context.Context, clients []domain.Client) (domain.SyncCounts, error) {
+	if len(clients) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(clients))
+	for _, cl := range clients {
+		rows = append(rows, []any{cl.ID, cl.WorkspaceID, cl.Name, cl.Archived, cl.At.UTC()})
+	}
+	counts, err := s.upsertRows(ctx, Upsert{
+		Table:   "toggl_clients",
+		Columns: []string{"id", "workspace_id", "name", "archived", "at"},
+		Update:  []string{"workspace_id", "name", "archived", "at"},
+	}, rows, nil)
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("clients", counts)
+	return counts, nil
+}
+
+// SyncTags upserts tags.
+func (s *Store) SyncTags(ctx context.Context, tags []domain.Tag) (domain.SyncCounts, error) {
+	if len(tags) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(tags))
+	for _, t := range tags {
+		rows = append(rows, []any{t.ID, t.WorkspaceID, t.Name, t.At.UTC()})
+	}
+	counts, err := s.upsertRows(ctx, Upsert{
+		Table:   "toggl_tags",
+		Columns: []string{"id", "workspace_id", "name", "at"},
+		Update:  []string{"workspace_id", "name", "at"},
+	}, rows, nil)
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("tags", counts)
+	return counts, nil
+}
+
+// SyncTasks upserts tasks.
+func (s *Store) SyncTasks(ctx context.Context, tasks []domain.Task) (domain.SyncCounts, error) {
+	if len(tasks) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(tasks))
+	for _, t := range tasks {
+		rows = append(rows, []any{t.ID, t.WorkspaceID, t.ProjectID, t.Name, nullInt64(t.EstimatedSeconds), t.Active, t.At.UTC()})
+	}
+	counts, err := s.upsertRows(ctx, Upsert{
+		Table:   "toggl_tasks",
+		Columns: []string{"id", "workspace_id", "project_id", "name", "estimated_seconds", "active", "at"},
+		Update:  []string{"workspace_id", "project_id", "name", "estimated_seconds", "active", "at"},
+	}, rows, nil)
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("tasks", counts)
+	return counts, nil
+}
+
+// SyncUsers upserts users and replaces each user's
+// group memberships.
+func (s *Store) SyncUsers(ctx context.Context, users []domain.User) (domain.SyncCounts, error) {
+	if len(users) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, []any{u.ID, u.WorkspaceID, u.Name, u.Email, u.Active, u.Admin})
+	}
+	counts, err := s.upsertRows(ctx, Upsert{
+		Table:   "toggl_users",
+		Columns: []string{"id", "workspace_id", "name", "email", "active", "admin"},
+		Update:  []string{"workspace_id", "name", "email", "active", "admin"},
+	}, rows, func(ctx context.Context, tx *sql.Tx, lo, hi int) error {
+		return s.replaceGroupMembers(ctx, tx, users[lo:hi])
+	})
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("users", counts)
+	return counts, nil
+}
+
+// replaceGroupMembers rebuilds group membership for users.
+func (s *Store) replaceGroupMembers(ctx context.Context, tx *sql.Tx, users []domain.User) error {
+	ids := make([]any, 0, len(users))
+	var pairs []any
+	for _, u := range users {
+		ids = append(ids, u.ID)
+		for _, groupID := range u.GroupIDs {
+			pairs = append(pairs, groupID, u.ID)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		s.d.Rebind("DELETE FROM toggl_group_members WHERE user_id IN ("+Placeholders(len(ids))+")"), ids...,
+	); err != nil {
+		return err
+	}
+	return s.insertPairs(ctx, tx, "toggl_group_members", []string{"group_id", "user_id"}, pairs)
+}
+
+// SyncGroups upserts groups.
+func (s *Store) SyncGroups(ctx context.Context, groups []domain.Group) (domain.SyncCounts, error) {
+	if len(groups) == 0 {
+		return domain.SyncCounts{}, nil
+	}
+	rows := make([][]any, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, []any{g.ID, g.WorkspaceID, g.Name})
+	}
+	counts, err := s.upsertRows(ctx, Upsert{
+		Table:   "toggl_groups",
+		Columns: []string{"id", "workspace_id", "name"},
+		Update:  []string{"workspace_id", "name"},
+	}, rows, nil)
+	if err != nil {
+		return counts, err
+	}
+	s.logUpserted("groups", counts)
+	return counts, nil
+}
@@ -0,0 +1,119 @@
+// Package sqlsink implements the database sinks on top of database/sql.
+// Queries are written once with ? placeholders; a Dialect supplies the
+// few statements that differ between MySQL, PostgreSQL and SQLite.
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// DefaultBatchSize is the number of rows per multi-row upsert.
+const DefaultBatchSize = 500
+
+// Dialect holds the SQL that differs between databases.
+type Dialect interface {
+	// Name prefixes log messages, e.g. "mysql".
+	Name() string
+	// MaxParams is the limit on placeholders per statement.
+	MaxParams() int
+	// Rebind rewrites the ? placeholders of query for the driver.
+	Rebind(query string) string
+	// UpsertQuery returns a multi-row upsert of n rows into u.Table keyed
+	// by id. If Returning, the statement returns one row per inserted or
+	// updated row; otherwise it reports 1 affected row per insert and 2 per
+	// update, like MySQL.
+	UpsertQuery(u Upsert, n int) string
+	Returning() bool
+	// InsertIgnore returns an insert of rows groups of columns that skips
+	// rows already present.
+	InsertIgnore(table string, columns []string, rows int) string
+	// OnConflictUpdate returns the clause that turns an insert into an
+	// upsert overwriting columns when key already exists.
+	OnConflictUpdate(key []string, columns []string) string
+	// NullSafeEqual compares a and b, treating two NULLs as equal.
+	NullSafeEqual(a, b string) string
+	// JSONText selects the JSON column col as text.
+	JSONText(col string) string
+	// DayValue converts a segment day to a driver value for a DATE column.
+	DayValue(day time.Time) any
+	// WeekStart and MonthStart return the first day of the week (Monday)
+	// and month of the DATE expression day.
+	WeekStart(day string) string
+	MonthStart(day string) string
+	// InsertID runs an insert into a table with a generated id column and
+	// returns the new id.
+	InsertID(ctx context.Context, db *sql.DB, query string, args ...any) (int64, error)
+}
+
+// Store implements ports.Sink, the readers and the run, watermark and job
+// stores on an open database whose schema is migrated.
+type Store struct {
+	db        *sql.DB
+	d         Dialect
+	log       *slog.Logger
+	batchSize int
+	loc       *time.Location // day boundaries of toggl_daily_entry_segments
+}
+
+// New returns a store writing to db. batchSize is the number of rows per
+// multi-row upsert; <= 0 selects DefaultBatchSize. loc sets the midnight at
+// which entries are split into daily segments; nil selects UTC.
+func New(db *sql.DB, d Dialect, batchSize int, loc *time.Location, log *slog.Logger) *Store {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Store{db: db, d: d, log: log, batchSize: batchSize, loc: loc}
+}
+
+// Close closes the underlying DB.
+func (s *Store) Close() error { return s.db.Close() }
+
+func (s *Store) logUpserted(entity string, counts domain.SyncCounts) {
+	s.log.Info(s.d.Name()+" sink upserted "+entity,
+		slog.Int("inserted", counts.Inserted),
+		slog.Int("updated", counts.Updated),
+		slog.Int("unchanged", counts.Unchanged),
+	)
+}
+
+// Placeholders returns "?, ?, ..." with n placeholders.
+func Placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return "?" + strings.Repeat(", ?", n-1)
+}
+
+// ValueGroups returns n parenthesized groups of width placeholders,
+// e.g. "(?, ?), (?, ?)".
+func ValueGroups(n, width int) string {
+	group := "(" + Placeholders(width) + ")"
+	return group + strings.Repeat(", "+group, n-1)
+}
+
+// Numbered rewrites the ? placeholders of query to $1, $2, ... Queries
+// must not contain ? elsewhere.
+func Numbered(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + len(query)/4)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"toggl-scraper/internal/domain"
+)
+
+// versionedColumns are the entry columns copied into a version; a change to
+// any of them closes the open version.
+var versionedColumns = []string{"description", "project_id", "tags", "start", "stop", "duration_sec"}
+
+// recordVersions brings toggl_time_entry_versions in line with the current
+// rows of the given entry IDs: an open version that no longer matches its
+// live entry is closed at now, and live entries without an open version get
+// a new one. An entry's first version is valid from its last edit in Toggl.
+// Running timers are not versioned until they stop.
+func (s *Store) recordVersions(ctx context.Context, tx *sql.Tx, ids []any, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	in := Placeholders(len(ids))
+	same := make([]string, len(versionedColumns))
+	for i, col := range versionedColumns {
+		same[i] = s.d.NullSafeEqual("e."+col, "v."+col)
+	}
+	if _, err := tx.ExecContext(ctx, s.d.Rebind(`
+UPDATE toggl_time_entry_versions AS v SET valid_to = ?
+WHERE v.valid_to IS NULL AND v.entry_id IN (`+in+`)
+  AND NOT EXISTS (
+    SELECT 1 FROM toggl_time_entries e
+    WHERE e.id = v.entry_id AND e.deleted_at IS NULL AND NOT e.is_running
+      AND `+strings.Join(same, " AND ")+`
+  )`), append([]any{now}, ids...)...); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, s.d.Rebind(`
+INSERT INTO toggl_time_entry_versions
+  (entry_id, description, project_id, tags, start, stop, duration_sec, at, valid_from)
+SELECT e.id, e.description, e.project_id, e.tags, e.start, e.stop, e.duration_sec, e.at,
+  CASE WHEN EXISTS (SELECT 1 FROM toggl_time_entry_versions p WHERE p.entry_id = e.id)
+    THEN ? ELSE COALESCE(e.at, ?) END
+FROM toggl_time_entries e
+WHERE e.id IN (`+in+`) AND e.deleted_at IS NULL AND NOT e.is_running
+  AND NOT EXISTS (
+    SELECT 1 FROM toggl_time_entry_versions v WHERE v.entry_id = e.id AND v.valid_to IS NULL
+  )`), append([]any{now, now}, ids...)...)
+	return err
+}
+
+// ListEntriesAsOf returns entries that started in [from, to) as they were
+// stored at asOf, ordered by start time. Description, project, tags and
+// times come from the version valid at asOf; other fields are current.
+// Tag IDs are not loaded, since membership is not versioned.
+func (s *Store) ListEntriesAsOf(ctx context.Context, asOf, from, to time.Time) ([]domain.TimeEntry, error) {
+	return s.queryEntries(ctx, `
+SELECT v.entry_id, v.description, v.project_id, e.task_id, e.workspace_id, `+s.d.JSONText("v.tags")+`, v.start, v.stop, v.duration_sec,
+  e.billable, e.user_id, v.at, e.created_with, e.is_running
+FROM toggl_time_entry_versions v
+JOIN toggl_time_entries e ON e.id = v.entry_id
+WHERE v.valid_from <= ? AND (v.valid_to IS NULL OR v.valid_to > ?)
+  AND v.start >= ? AND v.start < ?
+ORDER BY v.start, v.entry_id`, asOf.UTC(), asOf.UTC(), from.UTC(), to.UTC())
+}
+
+func entryIDs(entries []domain.TimeEntry) []any {
+	ids := make([]any, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
@@ -0,0 +1,33 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LoadWatermark returns the incremental sync watermark for a workspace.
+func (s *Store) LoadWatermark(ctx context.Context, workspaceID int64) (time.Time, bool, error) {
+	var mark time.Time
+	err := s.db.QueryRowContext(ctx,
+		s.d.Rebind("SELECT entries_at FROM sync_watermarks WHERE workspace_id = ?"), workspaceID,
+	).Scan(&mark)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return mark.UTC(), true, nil
+}
+
+// SaveWatermark stores the incremental sync watermark for a workspace.
+func (s *Store) SaveWatermark(ctx context.Context, workspaceID int64, mark time.Time) error {
+	q := s.d.Rebind(`
+INSERT INTO sync_watermarks (workspace_id, entries_at, updated_at)
+VALUES (?, ?, ?)
+` + s.d.OnConflictUpdate([]string{"workspace_id"}, []string{"entries_at", "updated_at"}))
+	_, err := s.db.ExecContext(ctx, q, workspaceID, mark.UTC(), time.Now().UTC())
+	return err
+}
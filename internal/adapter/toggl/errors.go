@@ -0,0 +1,98 @@
+package toggl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxErrorBody bounds how much of an error response body is kept.
+const maxErrorBody = 1024
+
+// APIError carries the details shared by all Toggl API failures.
+// Callers normally match one of the more specific types with errors.As.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Body       string // truncated to maxErrorBody bytes
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("toggl: %s: status %d: %s", e.Path, e.StatusCode, e.Body)
+}
+
+// AuthError reports a rejected API token (401).
+type AuthError struct{ APIError }
+
+func (e *AuthError) Error() string {
+	return "toggl: unauthorized (check TOGGL_API_TOKEN): " + e.APIError.Error()
+}
+
+// ForbiddenError reports that the token has no access to the requested
+// resource, typically the configured workspace (403).
+type ForbiddenError struct{ APIError }
+
+func (e *ForbiddenError) Error() string { return "toggl: forbidden: " + e.APIError.Error() }
+
+// RateLimitError reports an exhausted request quota (429). RetryAfter is
+// zero when Toggl did not send a Retry-After header.
+type RateLimitError struct {
+	APIError
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string { return "toggl: rate limited: " + e.APIError.Error() }
+
+// ServerError reports a 5xx response from Toggl.
+type ServerError struct{ APIError }
+
+func (e *ServerError) Error() string { return "toggl: server error: " + e.APIError.Error() }
+
+// NetworkError reports a request that got no response from Toggl, e.g.
+// a DNS failure, a refused connection or a timeout, once retries are
+// exhausted. Cancellation by the caller is not wrapped.
+type NetworkError struct {
+	Path string
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("toggl: %s: network error: %v", e.Path, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// DecodeError reports a 200 response whose body could not be decoded.
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("toggl: %s: decoding response: %v", e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// newStatusError builds the typed error for a non-200 response.
+func newStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	base := APIError{
+		StatusCode: resp.StatusCode,
+		Path:       resp.Request.URL.Path,
+		Body:       string(body),
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return &AuthError{base}
+	case resp.StatusCode == http.StatusForbidden:
+		return &ForbiddenError{base}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		ra, _ := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return &RateLimitError{APIError: base, RetryAfter: ra}
+	case resp.StatusCode >= 500:
+		return &ServerError{base}
+	}
+	return &base
+}
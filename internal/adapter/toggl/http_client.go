@@ -1,6 +1,7 @@
 package toggl
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -10,6 +11,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"toggl-scraper/internal/domain"
@@ -24,16 +26,33 @@ type Client struct {
 	log       *slog.Logger
 }
 
-func NewClient(baseURL, apiToken string, workspaceID int64, log *slog.Logger) *Client {
+// NewClient returns a Toggl client. Transient failures (429, 502, 503, 504
+// and network errors) are retried according to retry; a zero RetryPolicy
+// selects DefaultRetryPolicy.
+func NewClient(baseURL, apiToken string, workspaceID int64, retry RetryPolicy, log *slog.Logger) *Client {
 	if baseURL == "" {
 		baseURL = "https://api.track.toggl.com"
 	}
+	if retry == (RetryPolicy{}) {
+		retry = DefaultRetryPolicy
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if retry.MaxDelay <= 0 {
+		retry.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	// The 30s limit applies per attempt rather than to the whole request
+	// (as http.Client.Timeout would), so retries and Retry-After waits are
+	// bounded only by the caller's context.
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = 30 * time.Second
 	return &Client{
 		baseURL:   baseURL,
 		apiToken:  apiToken,
 		workspace: workspaceID,
 		http: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: &retryTransport{next: base, policy: retry, log: log},
 		},
 		log: log,
 	}
@@ -41,112 +60,232 @@ func NewClient(baseURL, apiToken string, workspaceID int64, log *slog.Logger) *C
 
 // ListTimeEntries fetches entries in [from, to].
 // Toggl v9: GET /api/v9/me/time_entries?start_date=...&end_date=...
+//
+// Toggl limits both how far back a single request may reach and how many
+// entries it returns, so the window is split into sub-ranges of at most
+// chunkSpan. Results are merged and deduplicated by ID. A sub-range that
+// hits the result cap is bisected until it fits; if it still does not fit
+// at minChunkSpan, a *TruncatedError is returned rather than partial data.
 func (c *Client) ListTimeEntries(ctx context.Context, from, to time.Time) ([]domain.TimeEntry, error) {
 	if c.apiToken == "" {
 		return nil, errors.New("missing api token")
 	}
-	u, err := url.Parse(c.baseURL)
+	raw, err := c.fetchChunked(ctx, time.Time{}, from, to)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = "/api/v9/me/time_entries"
-	q := u.Query()
-	q.Set("start_date", from.Format(time.RFC3339))
-	q.Set("end_date", to.Format(time.RFC3339))
-	u.RawQuery = q.Encode()
+	return toDomainEntries(raw), nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+// ListTimeEntriesSince fetches entries modified at or after since,
+// including entries deleted server-side.
+// Toggl v9: GET /api/v9/me/time_entries?since=<unix seconds>
+//
+// The result cap applies to since queries as well. When a single request
+// hits it, the query is narrowed by start date and goes through the same
+// chunking and bisection as ListTimeEntries.
+//
+// Toggl rejects since values older than roughly three months; in that case
+// the client falls back to fetching every entry started in [since, now),
+// which cannot see edits to entries started before since.
+func (c *Client) ListTimeEntriesSince(ctx context.Context, since time.Time) ([]domain.TimeEntry, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	if time.Since(since) > maxSinceAge {
+		c.log.Warn("watermark older than toggl since limit, falling back to window fetch", slog.Time("since", since))
+		return c.ListTimeEntries(ctx, since, time.Now().UTC())
+	}
+	raw, err := c.fetchTimeEntries(ctx, since, time.Time{}, time.Time{})
 	if err != nil {
 		return nil, err
 	}
-	// Basic auth: token:api_token
-	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", c.apiToken, "api_token")))
-	req.Header.Set("Authorization", "Basic "+auth)
-	req.Header.Set("Accept", "application/json")
+	if len(raw) >= maxEntriesPerRequest {
+		c.log.Debug("toggl since query truncated, splitting by start date",
+			slog.Time("since", since), slog.Int("count", len(raw)))
+		if raw, err = c.fetchSinceChunked(ctx, since); err != nil {
+			return nil, err
+		}
+	}
+	return toDomainEntries(raw), nil
+}
 
-	resp, err := c.http.Do(req)
+// fetchSinceChunked fetches entries modified at or after since in start
+// date sub-ranges. Changes to entries started more than maxSinceAge ago
+// are fetched in one request bounded by `before`, since the chunked range
+// has to start somewhere; if that request is truncated too, a
+// *TruncatedError is returned.
+func (c *Client) fetchSinceChunked(ctx context.Context, since time.Time) ([]rawTimeEntry, error) {
+	now := time.Now().UTC()
+	floor := now.Add(-maxSinceAge)
+	older, err := c.fetchTimeEntries(ctx, since, time.Time{}, floor)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return nil, fmt.Errorf("toggl: unexpected status %d: %s", resp.StatusCode, string(body))
+	if len(older) >= maxEntriesPerRequest {
+		return nil, &TruncatedError{To: floor, Count: len(older)}
 	}
-	dec := json.NewDecoder(resp.Body)
-	var raw []rawTimeEntry
-	if err := dec.Decode(&raw); err != nil {
+	recent, err := c.fetchChunked(ctx, since, floor, now.Add(24*time.Hour))
+	if err != nil {
 		return nil, err
 	}
-	// Map to domain
-	out := make([]domain.TimeEntry, 0, len(raw))
-	for _, r := range raw {
-		var stopPtr *time.Time
-		if r.Stop != nil {
-			stop := *r.Stop
-			stopPtr = &stop
-		}
-		var projectPtr *int64
-		if r.ProjectID != nil {
-			p := *r.ProjectID
-			projectPtr = &p
-		}
-		var wsPtr *int64
-		if r.WorkspaceID != nil {
-			w := *r.WorkspaceID
-			wsPtr = &w
+	return mergeEntries(older, recent), nil
+}
+
+// fetchChunked fetches entries started in [from, to) in sub-ranges of at
+// most chunkSpan, restricted to entries modified at or after since unless
+// since is zero.
+func (c *Client) fetchChunked(ctx context.Context, since, from, to time.Time) ([]rawTimeEntry, error) {
+	var out []rawTimeEntry
+	for _, w := range splitWindow(from, to, chunkSpan) {
+		raw, err := c.fetchWindow(ctx, since, w.from, w.to)
+		if err != nil {
+			return nil, err
 		}
-		out = append(out, domain.TimeEntry{
-			ID:          r.ID,
-			Description: r.Description,
-			ProjectID:   projectPtr,
-			WorkspaceID: wsPtr,
-			Tags:        r.Tags,
-			Start:       r.Start,
-			Stop:        stopPtr,
-			DurationSec: r.Duration,
-		})
+		out = mergeEntries(out, raw)
 	}
 	return out, nil
 }
 
-// ListProjects fetches projects accessible to the configured token.
-// If a workspace ID is configured, it scopes the request to that workspace.
-func (c *Client) ListProjects(ctx context.Context) ([]domain.Project, error) {
-	if c.apiToken == "" {
-		return nil, errors.New("missing api token")
+// mergeEntries appends raw to out, replacing entries already present by
+// ID. Entries spanning a chunk boundary may be returned twice.
+func mergeEntries(out, raw []rawTimeEntry) []rawTimeEntry {
+	seen := make(map[int64]int, len(out))
+	for i, r := range out {
+		seen[r.ID] = i
 	}
-	u, err := url.Parse(c.baseURL)
+	for _, r := range raw {
+		if i, ok := seen[r.ID]; ok {
+			out[i] = r
+			continue
+		}
+		seen[r.ID] = len(out)
+		out = append(out, r)
+	}
+	return out
+}
+
+// fetchWindow fetches a single sub-range, bisecting it while the response
+// appears to be truncated by the server-side result cap.
+func (c *Client) fetchWindow(ctx context.Context, since, from, to time.Time) ([]rawTimeEntry, error) {
+	raw, err := c.fetchTimeEntries(ctx, since, from, to)
 	if err != nil {
 		return nil, err
 	}
-	if c.workspace != 0 {
-		u.Path = fmt.Sprintf("/api/v9/workspaces/%d/projects", c.workspace)
-	} else {
-		u.Path = "/api/v9/me/projects"
+	if len(raw) < maxEntriesPerRequest {
+		return raw, nil
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if to.Sub(from) <= minChunkSpan {
+		return nil, &TruncatedError{From: from, To: to, Count: len(raw)}
+	}
+	mid := from.Add(to.Sub(from) / 2)
+	c.log.Debug("toggl time entries truncated, splitting window",
+		slog.Time("from", from), slog.Time("to", to), slog.Int("count", len(raw)))
+	left, err := c.fetchWindow(ctx, since, from, mid)
 	if err != nil {
 		return nil, err
 	}
-	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", c.apiToken, "api_token")))
-	req.Header.Set("Authorization", "Basic "+auth)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.http.Do(req)
+	right, err := c.fetchWindow(ctx, since, mid, to)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return nil, fmt.Errorf("toggl: unexpected status %d: %s", resp.StatusCode, string(body))
+	return append(left, right...), nil
+}
+
+// fetchTimeEntries performs one GET /api/v9/me/time_entries request for
+// entries started in [from, to). A zero since is left out; a zero from
+// sends to as `before`, and a zero to leaves the start date open.
+func (c *Client) fetchTimeEntries(ctx context.Context, since, from, to time.Time) ([]rawTimeEntry, error) {
+	q := url.Values{}
+	if !since.IsZero() {
+		q.Set("since", strconv.FormatInt(since.Unix(), 10))
+	}
+	switch {
+	case !from.IsZero():
+		q.Set("start_date", from.Format(time.RFC3339))
+		q.Set("end_date", to.Format(time.RFC3339))
+	case !to.IsZero():
+		q.Set("before", to.Format(time.RFC3339))
+	}
+	var raw []rawTimeEntry
+	if err := c.getJSON(ctx, "/api/v9/me/time_entries", q, &raw); err != nil {
+		return nil, err
 	}
+	return raw, nil
+}
+
+// toDomainEntries maps Toggl v9 time entries to the domain model.
+func toDomainEntries(raw []rawTimeEntry) []domain.TimeEntry {
+	out := make([]domain.TimeEntry, 0, len(raw))
+	for _, r := range raw {
+		out = append(out, toDomainEntry(r))
+	}
+	return out
+}
 
-	dec := json.NewDecoder(resp.Body)
+// toDomainEntry maps a Toggl v9 time entry to the domain model.
+func toDomainEntry(r rawTimeEntry) domain.TimeEntry {
+	var stopPtr *time.Time
+	if r.Stop != nil {
+		stop := *r.Stop
+		stopPtr = &stop
+	}
+	var projectPtr *int64
+	if r.ProjectID != nil {
+		p := *r.ProjectID
+		projectPtr = &p
+	}
+	var taskPtr *int64
+	if r.TaskID != nil {
+		t := *r.TaskID
+		taskPtr = &t
+	}
+	var wsPtr *int64
+	if r.WorkspaceID != nil {
+		w := *r.WorkspaceID
+		wsPtr = &w
+	}
+	var userPtr *int64
+	if r.UserID != nil {
+		u := *r.UserID
+		userPtr = &u
+	}
+	var deletedPtr *time.Time
+	if r.ServerDeletedAt != nil {
+		d := *r.ServerDeletedAt
+		deletedPtr = &d
+	}
+	return domain.TimeEntry{
+		ID:          r.ID,
+		Description: r.Description,
+		ProjectID:   projectPtr,
+		TaskID:      taskPtr,
+		WorkspaceID: wsPtr,
+		Tags:        r.Tags,
+		TagIDs:      r.TagIDs,
+		Start:       r.Start,
+		Stop:        stopPtr,
+		DurationSec: r.Duration,
+		Billable:    r.Billable,
+		UserID:      userPtr,
+		At:          r.At,
+		DeletedAt:   deletedPtr,
+		CreatedWith: r.CreatedWith,
+	}
+}
+
+// ListProjects fetches projects accessible to the configured token.
+// If a workspace ID is configured, it scopes the request to that workspace.
+func (c *Client) ListProjects(ctx context.Context) ([]domain.Project, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	path := "/api/v9/me/projects"
+	if c.workspace != 0 {
+		path = fmt.Sprintf("/api/v9/workspaces/%d/projects", c.workspace)
+	}
 	var raw []rawProject
-	if err := dec.Decode(&raw); err != nil {
+	if err := c.getJSON(ctx, path, nil, &raw); err != nil {
 		return nil, err
 	}
 
@@ -171,16 +310,254 @@ func (c *Client) ListProjects(ctx context.Context) ([]domain.Project, error) {
 	return out, nil
 }
 
+// ListClients fetches clients accessible to the configured token.
+// If a workspace ID is configured, it scopes the request to that workspace.
+func (c *Client) ListClients(ctx context.Context) ([]domain.Client, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	path := "/api/v9/me/clients"
+	if c.workspace != 0 {
+		path = fmt.Sprintf("/api/v9/workspaces/%d/clients", c.workspace)
+	}
+	var raw []rawClient
+	if err := c.getJSON(ctx, path, nil, &raw); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.Client, 0, len(raw))
+	for _, cl := range raw {
+		out = append(out, domain.Client{
+			ID:          cl.ID,
+			WorkspaceID: cl.WorkspaceID,
+			Name:        cl.Name,
+			Archived:    cl.Archived,
+			At:          cl.At,
+		})
+	}
+	return out, nil
+}
+
+// ListTags fetches tags accessible to the configured token.
+// If a workspace ID is configured, it scopes the request to that workspace.
+func (c *Client) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	path := "/api/v9/me/tags"
+	if c.workspace != 0 {
+		path = fmt.Sprintf("/api/v9/workspaces/%d/tags", c.workspace)
+	}
+	var raw []rawTag
+	if err := c.getJSON(ctx, path, nil, &raw); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.Tag, 0, len(raw))
+	for _, t := range raw {
+		out = append(out, domain.Tag{
+			ID:          t.ID,
+			WorkspaceID: t.WorkspaceID,
+			Name:        t.Name,
+			At:          t.At,
+		})
+	}
+	return out, nil
+}
+
+// ListTasks fetches tasks accessible to the configured token.
+// With a workspace ID configured, it pages through
+// /api/v9/workspaces/{id}/tasks; otherwise it uses /api/v9/me/tasks.
+func (c *Client) ListTasks(ctx context.Context) ([]domain.Task, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	var raw []rawTask
+	if c.workspace == 0 {
+		if err := c.getJSON(ctx, "/api/v9/me/tasks", nil, &raw); err != nil {
+			return nil, err
+		}
+	} else {
+		path := fmt.Sprintf("/api/v9/workspaces/%d/tasks", c.workspace)
+		for page := 1; ; page++ {
+			q := url.Values{}
+			q.Set("page", strconv.Itoa(page))
+			q.Set("per_page", strconv.Itoa(tasksPerPage))
+			var resp rawTaskPage
+			if err := c.getJSON(ctx, path, q, &resp); err != nil {
+				return nil, err
+			}
+			raw = append(raw, resp.Data...)
+			if len(resp.Data) < tasksPerPage || len(raw) >= resp.TotalCount {
+				break
+			}
+		}
+	}
+
+	out := make([]domain.Task, 0, len(raw))
+	for _, t := range raw {
+		var est *int64
+		if t.EstimatedSeconds != nil {
+			v := *t.EstimatedSeconds
+			est = &v
+		}
+		out = append(out, domain.Task{
+			ID:               t.ID,
+			WorkspaceID:      t.WorkspaceID,
+			ProjectID:        t.ProjectID,
+			Name:             t.Name,
+			EstimatedSeconds: est,
+			Active:           t.Active,
+			At:               t.At,
+		})
+	}
+	return out, nil
+}
+
+// ListUsers fetches the members of the configured workspace, including
+// their group memberships. Without a workspace ID only the token owner is
+// returned, since user listings are workspace-scoped.
+func (c *Client) ListUsers(ctx context.Context) ([]domain.User, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	if c.workspace == 0 {
+		var me rawMe
+		if err := c.getJSON(ctx, "/api/v9/me", nil, &me); err != nil {
+			return nil, err
+		}
+		return []domain.User{{
+			ID:          me.ID,
+			WorkspaceID: me.DefaultWorkspaceID,
+			Name:        me.FullName,
+			Email:       me.Email,
+			Active:      true,
+		}}, nil
+	}
+	path := fmt.Sprintf("/api/v9/workspaces/%d/workspace_users", c.workspace)
+	var raw []rawWorkspaceUser
+	if err := c.getJSON(ctx, path, nil, &raw); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.User, 0, len(raw))
+	for _, u := range raw {
+		out = append(out, domain.User{
+			ID:          u.UserID,
+			WorkspaceID: u.WorkspaceID,
+			Name:        u.Name,
+			Email:       u.Email,
+			Active:      u.Active,
+			Admin:       u.Admin,
+			GroupIDs:    u.GroupIDs,
+		})
+	}
+	return out, nil
+}
+
+// ListGroups fetches the groups of the configured workspace. Groups only
+// exist within a workspace, so nothing is returned without a workspace ID.
+func (c *Client) ListGroups(ctx context.Context) ([]domain.Group, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	if c.workspace == 0 {
+		return nil, nil
+	}
+	path := fmt.Sprintf("/api/v9/workspaces/%d/groups", c.workspace)
+	var raw []rawGroup
+	if err := c.getJSON(ctx, path, nil, &raw); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.Group, 0, len(raw))
+	for _, g := range raw {
+		out = append(out, domain.Group{
+			ID:          g.ID,
+			WorkspaceID: g.WorkspaceID,
+			Name:        g.Name,
+		})
+	}
+	return out, nil
+}
+
+// getJSON performs an authenticated GET against path and decodes the JSON
+// response into v. Non-200 responses are returned as typed errors (see
+// errors.go); transport failures as *NetworkError and decoding failures as
+// *DecodeError.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	_, err := c.doJSON(ctx, http.MethodGet, path, query, nil, v)
+	return err
+}
+
+// doJSON performs an authenticated request with an optional JSON body and
+// decodes the JSON response into v. It returns the response headers so
+// callers can follow header-based pagination.
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body, v any) (http.Header, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path
+	u.RawQuery = query.Encode()
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		// bytes.Reader lets the retry transport replay the body.
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	// Basic auth: token:api_token
+	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", c.apiToken, "api_token")))
+	req.Header.Set("Authorization", "Basic "+auth)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &NetworkError{Path: path, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, &DecodeError{Path: path, Err: err}
+	}
+	return resp.Header, nil
+}
+
 // rawTimeEntry mirrors the JSON from Toggl v9.
 type rawTimeEntry struct {
 	ID          int64      `json:"id"`
 	Description string     `json:"description"`
 	ProjectID   *int64     `json:"project_id"`
+	TaskID      *int64     `json:"task_id"`
 	WorkspaceID *int64     `json:"workspace_id"`
 	Tags        []string   `json:"tags"`
+	TagIDs      []int64    `json:"tag_ids"`
 	Start       time.Time  `json:"start"`
 	Stop        *time.Time `json:"stop"`
 	Duration    int64      `json:"duration"`
+	Billable    bool       `json:"billable"`
+	UserID      *int64     `json:"user_id"`
+	At          time.Time  `json:"at"`
+	// ServerDeletedAt is only populated for deleted entries returned by
+	// `since` queries.
+	ServerDeletedAt *time.Time `json:"server_deleted_at"`
+	CreatedWith     string     `json:"created_with"`
 }
 
 type rawProject struct {
@@ -193,3 +570,62 @@ type rawProject struct {
 	ClientID    *int64    `json:"client_id"`
 	At          time.Time `json:"at"`
 }
+
+type rawClient struct {
+	ID          int64     `json:"id"`
+	WorkspaceID int64     `json:"wid"`
+	Name        string    `json:"name"`
+	Archived    bool      `json:"archived"`
+	At          time.Time `json:"at"`
+}
+
+type rawTag struct {
+	ID          int64     `json:"id"`
+	WorkspaceID int64     `json:"workspace_id"`
+	Name        string    `json:"name"`
+	At          time.Time `json:"at"`
+}
+
+// tasksPerPage is the page size used when listing workspace tasks.
+const tasksPerPage = 200
+
+type rawTask struct {
+	ID               int64     `json:"id"`
+	WorkspaceID      int64     `json:"workspace_id"`
+	ProjectID        int64     `json:"project_id"`
+	Name             string    `json:"name"`
+	EstimatedSeconds *int64    `json:"estimated_seconds"`
+	Active           bool      `json:"active"`
+	At               time.Time `json:"at"`
+}
+
+// rawTaskPage is the paginated envelope of /workspaces/{id}/tasks.
+type rawTaskPage struct {
+	Data       []rawTask `json:"data"`
+	TotalCount int       `json:"total_count"`
+}
+
+type rawMe struct {
+	ID                 int64  `json:"id"`
+	Email              string `json:"email"`
+	FullName           string `json:"fullname"`
+	DefaultWorkspaceID int64  `json:"default_workspace_id"`
+}
+
+// rawWorkspaceUser is a membership record; UserID is the global user ID
+// referenced by time entries, not the membership ID.
+type rawWorkspaceUser struct {
+	UserID      int64   `json:"uid"`
+	WorkspaceID int64   `json:"wid"`
+	Name        string  `json:"name"`
+	Email       string  `json:"email"`
+	Active      bool    `json:"active"`
+	Admin       bool    `json:"admin"`
+	GroupIDs    []int64 `json:"group_ids"`
+}
+
+type rawGroup struct {
+	ID          int64  `json:"group_id"`
+	WorkspaceID int64  `json:"workspace_id"`
+	Name        string `json:"name"`
+}
@@ -0,0 +1,174 @@
+package toggl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"toggl-scraper/internal/domain"
+	"toggl-scraper/internal/ports"
+)
+
+// reportsPageSize is the page size requested from the Reports API v3.
+const reportsPageSize = 50
+
+// ReportsClient implements ports.TogglClient like Client, but fetches time
+// entries for the whole workspace through the Reports API v3 detailed
+// search instead of /me/time_entries, which only returns the token owner's
+// entries. All other methods are inherited from Client.
+type ReportsClient struct {
+	*Client
+}
+
+// NewReportsClient wraps c. The client must be configured with a
+// workspace ID, since the Reports API is workspace-scoped.
+func NewReportsClient(c *Client) (*ReportsClient, error) {
+	if c.workspace == 0 {
+		return nil, errors.New("toggl: reports mode requires a workspace ID")
+	}
+	return &ReportsClient{Client: c}, nil
+}
+
+// ListTimeEntries fetches all workspace entries starting in [from, to).
+// Reports API v3: POST /reports/api/v3/workspace/{id}/search/time_entries
+//
+// The search is date-based, so the requested dates are widened by a day
+// on each side to cover timezone offsets and the result is filtered to the
+// exact window. Pagination follows X-Next-ID / X-Next-Row-Number until
+// Toggl stops returning them.
+func (c *ReportsClient) ListTimeEntries(ctx context.Context, from, to time.Time) ([]domain.TimeEntry, error) {
+	if c.apiToken == "" {
+		return nil, errors.New("missing api token")
+	}
+	// Rows carry tag IDs only; resolve names so entries match the v9 shape.
+	tags, err := c.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagNames := make(map[int64]string, len(tags))
+	for _, t := range tags {
+		tagNames[t.ID] = t.Name
+	}
+
+	path := fmt.Sprintf("/reports/api/v3/workspace/%d/search/time_entries", c.workspace)
+	req := reportsSearchRequest{
+		StartDate: from.UTC().AddDate(0, 0, -1).Format("2006-01-02"),
+		EndDate:   to.UTC().AddDate(0, 0, 1).Format("2006-01-02"),
+		PageSize:  reportsPageSize,
+	}
+	ws := c.workspace
+
+	var (
+		out  []domain.TimeEntry
+		seen = make(map[int64]bool)
+	)
+	for {
+		var rows []rawReportRow
+		hdr, err := c.doJSON(ctx, http.MethodPost, path, nil, req, &rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			for _, te := range row.TimeEntries {
+				if te.Start.Before(from) || !te.Start.Before(to) || seen[te.ID] {
+					continue
+				}
+				seen[te.ID] = true
+				out = append(out, row.toDomain(te, ws, tagNames))
+			}
+		}
+		nextID, nextRow := hdr.Get("X-Next-ID"), hdr.Get("X-Next-Row-Number")
+		if nextID == "" || nextRow == "" {
+			break
+		}
+		if req.FirstID, err = strconv.ParseInt(nextID, 10, 64); err != nil {
+			return nil, &DecodeError{Path: path, Err: fmt.Errorf("X-Next-ID: %w", err)}
+		}
+		if req.FirstRowNumber, err = strconv.ParseInt(nextRow, 10, 64); err != nil {
+			return nil, &DecodeError{Path: path, Err: fmt.Errorf("X-Next-Row-Number: %w", err)}
+		}
+		c.log.Debug("toggl reports next page", slog.Int64("first_id", req.FirstID), slog.Int64("first_row_number", req.FirstRowNumber))
+	}
+	return out, nil
+}
+
+// ListTimeEntriesSince always returns ports.ErrSinceUnsupported: the
+// Reports API filters by start date only, so it would miss edits to older
+// entries and never report deletions. Incremental syncs fall back to
+// re-fetching a lookback window instead.
+func (c *ReportsClient) ListTimeEntriesSince(ctx context.Context, since time.Time) ([]domain.TimeEntry, error) {
+	return nil, ports.ErrSinceUnsupported
+}
+
+// reportsSearchRequest is the body of a detailed report search.
+type reportsSearchRequest struct {
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	PageSize       int    `json:"page_size"`
+	FirstID        int64  `json:"first_id,omitempty"`
+	FirstRowNumber int64  `json:"first_row_number,omitempty"`
+}
+
+// rawReportRow groups entries sharing user, project, description and tags.
+type rawReportRow struct {
+	UserID      int64                `json:"user_id"`
+	ProjectID   *int64               `json:"project_id"`
+	TaskID      *int64               `json:"task_id"`
+	Billable    bool                 `json:"billable"`
+	Description string               `json:"description"`
+	TagIDs      []int64              `json:"tag_ids"`
+	TimeEntries []rawReportTimeEntry `json:"time_entries"`
+}
+
+type rawReportTimeEntry struct {
+	ID      int64      `json:"id"`
+	Seconds int64      `json:"seconds"`
+	Start   time.Time  `json:"start"`
+	Stop    *time.Time `json:"stop"`
+	At      time.Time  `json:"at"`
+}
+
+func (r rawReportRow) toDomain(te rawReportTimeEntry, workspaceID int64, tagNames map[int64]string) domain.TimeEntry {
+	tags := make([]string, 0, len(r.TagIDs))
+	for _, id := range r.TagIDs {
+		if name, ok := tagNames[id]; ok {
+			tags = append(tags, name)
+		}
+	}
+	var stopPtr *time.Time
+	if te.Stop != nil {
+		stop := *te.Stop
+		stopPtr = &stop
+	}
+	var projectPtr *int64
+	if r.ProjectID != nil {
+		p := *r.ProjectID
+		projectPtr = &p
+	}
+	var taskPtr *int64
+	if r.TaskID != nil {
+		t := *r.TaskID
+		taskPtr = &t
+	}
+	user := r.UserID
+	ws := workspaceID
+	return domain.TimeEntry{
+		ID:          te.ID,
+		Description: r.Description,
+		ProjectID:   projectPtr,
+		TaskID:      taskPtr,
+		WorkspaceID: &ws,
+		Tags:        tags,
+		TagIDs:      r.TagIDs,
+		Start:       te.Start,
+		Stop:        stopPtr,
+		DurationSec: te.Seconds,
+		Billable:    r.Billable,
+		UserID:      &user,
+		At:          te.At,
+	}
+}
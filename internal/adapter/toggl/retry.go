@@ -0,0 +1,144 @@
+package toggl
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the Toggl client retries transient failures.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first; <= 1 disables retries
+	BaseDelay   time.Duration // backoff before the second attempt
+	MaxDelay    time.Duration // upper bound for a single backoff, including Retry-After waits
+}
+
+// DefaultRetryPolicy is used when NewClient receives a zero RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// retryTransport retries requests that failed with a network error or a
+// rate-limit / gateway status. Auth errors (401, 403) are never retried.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy RetryPolicy
+	log    *slog.Logger
+}
+
+// RoundTrip sends a clone of req per attempt, so req itself is never
+// modified. A request body that cannot be replayed through GetBody is only
+// sent once.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		resp, err := t.next.RoundTrip(r)
+		if !replayable || attempt >= t.policy.MaxAttempts || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+		wait := t.backoff(attempt)
+		if resp != nil {
+			// Honor Retry-After, but never park a sync for longer than a
+			// regular backoff would.
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = min(ra, t.policy.MaxDelay)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			// The caller would time out while waiting; report this
+			// attempt's outcome right away instead.
+			return resp, err
+		}
+		if resp != nil {
+			// Drain so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		attrs := []any{
+			slog.String("path", req.URL.Path),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		} else {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		}
+		t.log.Warn("toggl request failed, retrying", attrs...)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns an exponential delay with jitter for the given attempt.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.policy.BaseDelay << (attempt - 1)
+	if d <= 0 || d > t.policy.MaxDelay {
+		d = t.policy.MaxDelay
+	}
+	// Jitter in [d/2, d) to avoid synchronized retries.
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half)
+}
+
+// retryable reports whether a request outcome is worth retrying.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Cancellation and deadlines come from the caller, not from Toggl.
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given either as delay-seconds or
+// as an HTTP date.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
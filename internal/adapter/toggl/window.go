@@ -0,0 +1,53 @@
+package toggl
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// chunkSpan bounds the window of a single time entries request.
+	chunkSpan = 7 * 24 * time.Hour
+	// minChunkSpan is the smallest window fetchWindow will bisect down to.
+	minChunkSpan = time.Hour
+	// maxEntriesPerRequest is the result cap Toggl applies to a single
+	// time entries response. A response of this size is assumed truncated.
+	maxEntriesPerRequest = 1000
+	// maxSinceAge is how far back Toggl accepts the `since` parameter.
+	maxSinceAge = 90 * 24 * time.Hour
+)
+
+// TruncatedError reports a sub-range whose response hit the Toggl result
+// cap even at the smallest chunk size, so entries may be missing.
+type TruncatedError struct {
+	From  time.Time
+	To    time.Time
+	Count int
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("toggl: time entries truncated for [%s, %s): got %d entries",
+		e.From.Format(time.RFC3339), e.To.Format(time.RFC3339), e.Count)
+}
+
+type window struct {
+	from, to time.Time
+}
+
+// splitWindow splits [from, to) into consecutive sub-ranges of at most span.
+// An empty or inverted window yields a single sub-range so the request is
+// still made and Toggl can validate it.
+func splitWindow(from, to time.Time, span time.Duration) []window {
+	if !to.After(from) || span <= 0 {
+		return []window{{from: from, to: to}}
+	}
+	var out []window
+	for start := from; start.Before(to); start = start.Add(span) {
+		end := start.Add(span)
+		if end.After(to) {
+			end = to
+		}
+		out = append(out, window{from: start, to: end})
+	}
+	return out
+}
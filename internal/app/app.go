@@ -3,52 +3,179 @@ package app
 import (
     "context"
     "errors"
+    "fmt"
     "log/slog"
     "sync/atomic"
     "time"
 
+    "toggl-scraper/internal/adapter/fanout"
+    "toggl-scraper/internal/adapter/file"
     msql "toggl-scraper/internal/adapter/mysql"
+    "toggl-scraper/internal/adapter/parquet"
+    pg "toggl-scraper/internal/adapter/postgres"
+    "toggl-scraper/internal/adapter/sqlite"
     tg "toggl-scraper/internal/adapter/toggl"
     "toggl-scraper/internal/config"
+    "toggl-scraper/internal/domain"
     "toggl-scraper/internal/migrate"
+    "toggl-scraper/internal/ports"
     "toggl-scraper/internal/usecase"
 )
 
+// ErrSyncRunning is returned by RunOnce when another sync is in progress.
+var ErrSyncRunning = errors.New("sync already running")
+
+// store is a database sink that also keeps watermarks, run and job history
+// and tracks running timers.
+type store interface {
+    ports.Sink
+    ports.WatermarkStore
+    ports.RunRecorder
+    ports.RunningEntryReader
+    ports.JobStore
+}
+
 // App wires adapters and use cases.
 type App struct {
     log  *slog.Logger
     uc   *usecase.SyncUseCase
     // running is 0 when idle, 1 when a sync is in progress.
     running atomic.Int32
+    // jobs holds sync jobs started over HTTP until they are stored.
+    jobs *jobQueue
+    // tokens authorize HTTP requests unless noAuth opts out.
+    tokens []config.HTTPToken
+    noAuth bool
 }
 
 func New(log *slog.Logger, cfg config.Config) (*App, error) {
-    togglClient := tg.NewClient(cfg.Toggl.BaseURL, cfg.Toggl.APIToken, cfg.Toggl.WorkspaceID, log)
-    // Run migrations before opening the sink for use
-    if err := migrate.Run(context.Background(), cfg.MySQL.DSN, log); err != nil {
+    togglClient, err := newTogglClient(log, cfg)
+    if err != nil {
         return nil, err
     }
-    sink, err := msql.NewClient(context.Background(), cfg.MySQL.DSN, log)
+    primary, sink, err := openSinks(context.Background(), log, cfg)
     if err != nil {
         return nil, err
     }
 
     uc := &usecase.SyncUseCase{
-        Log:   log,
-        Toggl: togglClient,
-        Sink:  sink,
+        Log:         log,
+        Toggl:       togglClient,
+        Sink:        sink,
+        Watermarks:  primary,
+        Runs:        primary,
+        Running:     primary,
+        WorkspaceID: cfg.Toggl.WorkspaceID,
+        Lookback:    cfg.Sync.Lookback,
+    }
+
+    return &App{log: log, uc: uc, jobs: newJobQueue(primary), tokens: cfg.HTTP.Tokens, noAuth: cfg.HTTP.NoAuth}, nil
+}
+
+// newTogglClient builds the Toggl client for the configured entries source.
+func newTogglClient(log *slog.Logger, cfg config.Config) (ports.TogglClient, error) {
+    retry := tg.DefaultRetryPolicy
+    retry.MaxAttempts = cfg.Toggl.MaxAttempts
+    baseClient := tg.NewClient(cfg.Toggl.BaseURL, cfg.Toggl.APIToken, cfg.Toggl.WorkspaceID, retry, log)
+    if cfg.Toggl.EntriesSource == config.EntriesSourceReports {
+        return tg.NewReportsClient(baseClient)
     }
+    return baseClient, nil
+}
 
-    return &App{log: log, uc: uc}, nil
+// openSinks opens every configured sink target. The first target is the
+// primary store for watermarks and run history and must be a database;
+// with more than one target the returned sink fans out to all of them. An
+// optional target that fails to open is logged and left out.
+func openSinks(ctx context.Context, log *slog.Logger, cfg config.Config) (store, ports.Sink, error) {
+    if len(cfg.Sink.Targets) == 0 || cfg.Sink.Targets[0].Optional {
+        return nil, nil, errors.New("SINK (or MYSQL_DSN) is required")
+    }
+    var (
+        primary store
+        targets []fanout.Target
+    )
+    for i, t := range cfg.Sink.Targets {
+        s, err := openSink(ctx, log, cfg, t)
+        if err != nil {
+            if t.Optional {
+                log.Warn("skipping optional sink", slog.String("sink", t.Name), slog.String("error", err.Error()))
+                continue
+            }
+            return nil, nil, fmt.Errorf("sink %s: %w", t.Name, err)
+        }
+        if i == 0 {
+            st, ok := s.(store)
+            if !ok {
+                return nil, nil, fmt.Errorf("sink %s: the first SINK must be a database", t.Name)
+            }
+            primary = st
+        }
+        policy := fanout.Required
+        if t.Optional {
+            policy = fanout.Optional
+        }
+        targets = append(targets, fanout.Target{Name: t.Name, Sink: s, Policy: policy})
+    }
+    if len(targets) == 1 {
+        return primary, primary, nil
+    }
+    sink, err := fanout.New(log, targets...)
+    if err != nil {
+        return nil, nil, err
+    }
+    return primary, sink, nil
 }
 
-func (a *App) RunOnce(ctx context.Context, from, to time.Time) error {
+// openSink opens a single sink target, migrating databases first. File
+// and Parquet sinks have no schema to migrate.
+func openSink(ctx context.Context, log *slog.Logger, cfg config.Config, t config.SinkTarget) (ports.Sink, error) {
+    switch t.Driver {
+    case config.SinkFile:
+        return file.NewSink(t.DSN, log)
+    case config.SinkParquet:
+        return parquet.NewSink(t.DSN, log)
+    }
+    // Run migrations before opening the sink for use
+    if err := migrate.Run(ctx, t.Driver, t.DSN, log); err != nil {
+        return nil, err
+    }
+    return openDatabase(ctx, log, cfg, t)
+}
+
+// openDatabase opens a database sink target whose schema is migrated.
+// Databases split entries into daily segments at midnight in SYNC_TZ.
+func openDatabase(ctx context.Context, log *slog.Logger, cfg config.Config, t config.SinkTarget) (store, error) {
+    loc, err := time.LoadLocation(cfg.Sync.Timezone)
+    if err != nil {
+        return nil, fmt.Errorf("invalid SYNC_TZ: %w", err)
+    }
+    switch t.Driver {
+    case config.SinkPostgres:
+        return pg.NewClient(ctx, t.DSN, cfg.Sink.BatchSize, loc, log)
+    case config.SinkSQLite:
+        return sqlite.NewClient(ctx, t.DSN, cfg.Sink.BatchSize, loc, log)
+    default:
+        return msql.NewClient(ctx, t.DSN, cfg.Sink.BatchSize, loc, log)
+    }
+}
+
+func (a *App) RunOnce(ctx context.Context, trigger domain.SyncTrigger, from, to time.Time) (domain.SyncRun, error) {
     // Prevent overlapping runs across schedulers and HTTP triggers.
     if !a.tryBeginRun() {
-        return errors.New("sync already running")
+        return domain.SyncRun{}, ErrSyncRunning
+    }
+    defer a.endRun()
+    return a.uc.Run(ctx, trigger, from, to)
+}
+
+// RunIncremental syncs entries changed since the persisted watermark.
+func (a *App) RunIncremental(ctx context.Context, trigger domain.SyncTrigger) (domain.SyncRun, error) {
+    if !a.tryBeginRun() {
+        return domain.SyncRun{}, ErrSyncRunning
     }
     defer a.endRun()
-    return a.uc.Run(ctx, from, to)
+    return a.uc.RunIncremental(ctx, trigger, time.Now().UTC())
 }
 
 func (a *App) tryBeginRun() bool {
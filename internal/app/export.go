@@ -0,0 +1,116 @@
+package app
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "log/slog"
+    "time"
+
+    "toggl-scraper/internal/adapter/file"
+    "toggl-scraper/internal/config"
+    "toggl-scraper/internal/domain"
+    "toggl-scraper/internal/migrate"
+    "toggl-scraper/internal/ports"
+)
+
+// Export sources for ExportOptions.Source.
+const (
+    ExportFromToggl = "toggl"
+    ExportFromDB    = "db"
+)
+
+// ExportOptions selects what Export reads and how it writes it.
+type ExportOptions struct {
+    // Source is ExportFromToggl (default) or ExportFromDB, which reads the
+    // primary SINK database.
+    Source   string
+    Format   file.Format
+    From, To time.Time
+    // AsOf, when set, exports entries as the database stored them at that
+    // time instead of their current state. Requires ExportFromDB.
+    AsOf time.Time
+}
+
+// Export writes the time entries that started in [opts.From, opts.To) to w.
+// It does not touch the watermark or run history.
+func Export(ctx context.Context, log *slog.Logger, cfg config.Config, opts ExportOptions, w io.Writer) error {
+    var (
+        entries []domain.TimeEntry
+        err     error
+    )
+    if !opts.AsOf.IsZero() && opts.Source != ExportFromDB {
+        return errors.New("export: an as-of time requires the db source")
+    }
+    switch opts.Source {
+    case "", ExportFromToggl:
+        var client ports.TogglClient
+        client, err = newTogglClient(log, cfg)
+        if err != nil {
+            return err
+        }
+        entries, err = client.ListTimeEntries(ctx, opts.From, opts.To)
+        // Match the database export, which only has live entries and
+        // stores running timers flagged with a provisional duration.
+        entries = domain.MarkRunning(liveEntries(entries), time.Now().UTC())
+    case ExportFromDB:
+        entries, err = readEntries(ctx, log, cfg, opts)
+    default:
+        return fmt.Errorf("export: unsupported source %q (want toggl or db)", opts.Source)
+    }
+    if err != nil {
+        return err
+    }
+    log.Info("exporting time entries", slog.Int("count", len(entries)), slog.String("format", string(opts.Format)))
+    return file.WriteEntries(w, opts.Format, entries)
+}
+
+// readEntries reads live entries from the primary SINK database, or their
+// versions at opts.AsOf when set.
+func readEntries(ctx context.Context, log *slog.Logger, cfg config.Config, opts ExportOptions) ([]domain.TimeEntry, error) {
+    if len(cfg.Sink.Targets) == 0 {
+        return nil, errors.New("SINK (or MYSQL_DSN) is required to export from the database")
+    }
+    t := cfg.Sink.Targets[0]
+    if t.Driver == config.SinkFile || t.Driver == config.SinkParquet {
+        return nil, fmt.Errorf("sink %s: cannot read entries back", t.Name)
+    }
+    // Exporting never migrates: a read-only command should not change the
+    // schema of a database that a newer or older sync owns.
+    if err := migrate.Check(ctx, t.Driver, t.DSN); err != nil {
+        if errors.Is(err, migrate.ErrSchemaBehind) {
+            return nil, fmt.Errorf("sink %s: %w; run a sync with this version first", t.Name, err)
+        }
+        return nil, fmt.Errorf("sink %s: %w", t.Name, err)
+    }
+    s, err := openDatabase(ctx, log, cfg, t)
+    if err != nil {
+        return nil, fmt.Errorf("sink %s: %w", t.Name, err)
+    }
+    if c, ok := s.(io.Closer); ok {
+        defer c.Close()
+    }
+    if !opts.AsOf.IsZero() {
+        r, ok := s.(ports.EntryHistoryReader)
+        if !ok {
+            return nil, fmt.Errorf("sink %s: cannot read entry history", t.Name)
+        }
+        return r.ListEntriesAsOf(ctx, opts.AsOf, opts.From, opts.To)
+    }
+    r, ok := s.(ports.EntryReader)
+    if !ok {
+        return nil, fmt.Errorf("sink %s: cannot read entries back", t.Name)
+    }
+    return r.ListEntries(ctx, opts.From, opts.To)
+}
+
+func liveEntries(entries []domain.TimeEntry) []domain.TimeEntry {
+    out := entries[:0]
+    for _, e := range entries {
+        if e.DeletedAt == nil {
+            out = append(out, e)
+        }
+    }
+    return out
+}
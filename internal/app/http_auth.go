@@ -0,0 +1,80 @@
+package app
+
+import (
+    "crypto/sha256"
+    "crypto/subtle"
+    "log/slog"
+    "net/http"
+    "strings"
+
+    "toggl-scraper/internal/config"
+)
+
+// requireScope wraps next so that it only runs for requests with a bearer
+// token of the given scope. A sync token satisfies the read scope. Only an
+// explicit HTTP_AUTH=none lets every request through; without tokens every
+// request is rejected.
+func (a *App) requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if a.noAuth {
+            next(w, r)
+            return
+        }
+        tok, ok := bearerToken(r)
+        if !ok {
+            a.authFailed(w, r, http.StatusUnauthorized, "missing bearer token")
+            return
+        }
+        got, found := a.tokenScope(tok)
+        if !found {
+            a.authFailed(w, r, http.StatusUnauthorized, "invalid token")
+            return
+        }
+        if scope == config.ScopeSync && got != config.ScopeSync {
+            a.authFailed(w, r, http.StatusForbidden, "token lacks sync scope")
+            return
+        }
+        next(w, r)
+    }
+}
+
+// tokenScope returns the scope of the configured token equal to tok. It
+// compares SHA-256 digests in constant time and checks every token, so
+// neither the position nor the length of a match is observable.
+func (a *App) tokenScope(tok string) (string, bool) {
+    sum := sha256.Sum256([]byte(tok))
+    scope, found := "", false
+    for _, t := range a.tokens {
+        want := sha256.Sum256([]byte(t.Token))
+        if subtle.ConstantTimeCompare(sum[:], want[:]) == 1 && !found {
+            scope, found = t.Scope, true
+        }
+    }
+    return scope, found
+}
+
+// authFailed logs a rejected request and writes the error response.
+func (a *App) authFailed(w http.ResponseWriter, r *http.Request, status int, reason string) {
+    a.log.Warn("http auth failed",
+        slog.String("method", r.Method),
+        slog.String("path", r.URL.Path),
+        slog.String("remote", r.RemoteAddr),
+        slog.String("reason", reason),
+    )
+    kind := "forbidden"
+    if status == http.StatusUnauthorized {
+        kind = "unauthorized"
+        w.Header().Set("WWW-Authenticate", `Bearer realm="toggl-scraper"`)
+    }
+    writeJSON(w, status, map[string]any{"status": "error", "kind": kind, "error": reason})
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+    scheme, tok, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+    if !ok || !strings.EqualFold(scheme, "Bearer") {
+        return "", false
+    }
+    tok = strings.TrimSpace(tok)
+    return tok, tok != ""
+}
@@ -3,25 +3,41 @@ package app
 import (
     "context"
     "encoding/json"
+    "errors"
     "log/slog"
     "net/http"
+    "strconv"
     "time"
+
+    tg "toggl-scraper/internal/adapter/toggl"
+    "toggl-scraper/internal/config"
+    "toggl-scraper/internal/domain"
 )
 
+// ErrNoHTTPAuth is returned by HTTPServer when no tokens are configured
+// and authentication was not explicitly disabled.
+var ErrNoHTTPAuth = errors.New("http trigger server needs HTTP_SYNC_TOKENS, HTTP_READ_TOKENS or HTTP_TOKENS_FILE; set HTTP_AUTH=none to run it without authentication")
+
 // HTTPServer returns a configured http.Server that exposes endpoints to trigger syncs.
 // Call ListenAndServe on the returned server in a goroutine and Shutdown it on exit.
-func (a *App) HTTPServer(addr string) *http.Server {
+// It fails closed with ErrNoHTTPAuth unless tokens are configured or
+// authentication is explicitly disabled.
+func (a *App) HTTPServer(addr string) (*http.Server, error) {
+    if len(a.tokens) == 0 && !a.noAuth {
+        return nil, ErrNoHTTPAuth
+    }
     mux := http.NewServeMux()
 
-    mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+    mux.HandleFunc("/healthz", a.requireScope(config.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Content-Type", "text/plain; charset=utf-8")
         w.WriteHeader(http.StatusOK)
         _, _ = w.Write([]byte("ok"))
-    })
+    }))
 
     // /sync?from=...&to=...
-    // from/to accept RFC3339 or YYYY-MM-DD. If omitted, defaults to [now-24h, now].
-    mux.HandleFunc("/sync", func(w http.ResponseWriter, r *http.Request) {
+    // from/to accept RFC3339 or YYYY-MM-DD. If both are omitted, an
+    // incremental sync from the persisted watermark is run instead.
+    mux.HandleFunc("/sync", a.requireScope(config.ScopeSync, func(w http.ResponseWriter, r *http.Request) {
         if r.Method != http.MethodGet && r.Method != http.MethodPost {
             http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
             return
@@ -46,34 +62,230 @@ func (a *App) HTTPServer(addr string) *http.Server {
         }
 
         // Run sync
-        err := a.RunOnce(ctx, fromTime, toTime)
+        incremental := fromStr == "" && toStr == ""
+        resp := map[string]any{"mode": "window"}
+        var (
+            run domain.SyncRun
+            err error
+        )
+        if incremental {
+            resp["mode"] = "incremental"
+            run, err = a.RunIncremental(ctx, domain.TriggerHTTP)
+        } else {
+            resp["from"] = fromTime.Format(time.RFC3339)
+            resp["to"] = toTime.Format(time.RFC3339)
+            run, err = a.RunOnce(ctx, domain.TriggerHTTP, fromTime, toTime)
+        }
+        if run.Counts != nil {
+            if run.ID != 0 {
+                resp["run_id"] = run.ID
+            }
+            resp["changed"] = run.Changed()
+            resp["counts"] = countsJSON(run.Counts)
+            if len(run.Sinks) > 0 {
+                resp["sinks"] = sinksJSON(run.Sinks)
+            }
+        }
         w.Header().Set("Content-Type", "application/json; charset=utf-8")
         if err != nil {
-            // Distinguish concurrent run from other errors by message
-            status := http.StatusInternalServerError
-            if err.Error() == "sync already running" {
-                status = http.StatusConflict
+            status, kind := classifyError(err)
+            var rl *tg.RateLimitError
+            if errors.As(err, &rl) && rl.RetryAfter > 0 {
+                w.Header().Set("Retry-After", strconv.Itoa(int(rl.RetryAfter.Seconds())))
             }
             w.WriteHeader(status)
-            _ = json.NewEncoder(w).Encode(map[string]any{
-                "status": "error",
-                "error":  err.Error(),
-                "from":   fromTime.Format(time.RFC3339),
-                "to":     toTime.Format(time.RFC3339),
-            })
+            resp["status"] = "error"
+            resp["kind"] = kind
+            resp["error"] = err.Error()
+            _ = json.NewEncoder(w).Encode(resp)
             return
         }
         w.WriteHeader(http.StatusOK)
-        _ = json.NewEncoder(w).Encode(map[string]any{
-            "status": "ok",
-            "from":   fromTime.Format(time.RFC3339),
-            "to":     toTime.Format(time.RFC3339),
-        })
-    })
+        resp["status"] = "ok"
+        _ = json.NewEncoder(w).Encode(resp)
+    }))
+
+    // POST /jobs?from=...&to=...&timeout=...
+    // Queues a sync with the same parameters as /sync and returns its job
+    // ID right away; poll GET /jobs/{id} for progress.
+    mux.HandleFunc("POST /jobs", a.requireScope(config.ScopeSync, func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        var fromTime, toTime time.Time
+        if q.Get("from") != "" || q.Get("to") != "" {
+            toTime = parseEndHTTP(q.Get("to"), time.Now().UTC())
+            fromTime = parseStartHTTP(q.Get("from"), toTime.Add(-24*time.Hour))
+        }
+        var timeout time.Duration
+        if tStr := q.Get("timeout"); tStr != "" {
+            if d, err := time.ParseDuration(tStr); err == nil && d > 0 {
+                timeout = d
+            }
+        }
+        job, err := a.StartJob(fromTime, toTime, timeout)
+        if errors.Is(err, ErrJobQueueFull) {
+            writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "error", "error": err.Error()})
+            return
+        }
+        if err != nil {
+            writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "error": err.Error()})
+            return
+        }
+        w.Header().Set("Location", "/jobs/"+job.ID)
+        writeJSON(w, http.StatusAccepted, jobJSON(job))
+    }))
+
+    // GET /jobs/{id} returns the state, stage, counts and error of a job.
+    mux.HandleFunc("GET /jobs/{id}", a.requireScope(config.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
+        job, ok, err := a.Job(r.Context(), r.PathValue("id"))
+        if err != nil {
+            writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "error": err.Error()})
+            return
+        }
+        if !ok {
+            writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "error": "job not found"})
+            return
+        }
+        writeJSON(w, http.StatusOK, jobJSON(job))
+    }))
+
+    // DELETE /jobs/{id} cancels a queued or running job. A running job
+    // stops asynchronously; poll GET /jobs/{id} until it is cancelled.
+    mux.HandleFunc("DELETE /jobs/{id}", a.requireScope(config.ScopeSync, func(w http.ResponseWriter, r *http.Request) {
+        job, ok, err := a.CancelJob(r.Context(), r.PathValue("id"))
+        switch {
+        case errors.Is(err, ErrJobDone):
+            writeJSON(w, http.StatusConflict, jobJSON(job))
+        case err != nil:
+            writeJSON(w, http.StatusInternalServerError, map[string]any{"status": "error", "error": err.Error()})
+        case !ok:
+            writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "error": "job not found"})
+        default:
+            writeJSON(w, http.StatusAccepted, jobJSON(job))
+        }
+    }))
 
     srv := &http.Server{Addr: addr, Handler: loggingMiddleware(a.log, mux)}
+    if a.noAuth {
+        a.log.Warn("HTTP_AUTH=none: http trigger server authentication is DISABLED; anyone who can reach it can trigger and cancel syncs")
+    }
+
+    // Jobs run in the background until the server shuts down.
+    jobsCtx, stopJobs := context.WithCancel(context.Background())
+    go a.runJobs(jobsCtx)
+    srv.RegisterOnShutdown(stopJobs)
+
     a.log.Info("http trigger server configured", slog.String("addr", addr))
-    return srv
+    return srv, nil
+}
+
+// jobJSON renders a sync job for the JSON response.
+func jobJSON(j domain.Job) map[string]any {
+    resp := map[string]any{
+        "id":         j.ID,
+        "state":      string(j.State),
+        "mode":       j.Mode,
+        "created_at": j.CreatedAt.Format(time.RFC3339),
+    }
+    if !j.From.IsZero() {
+        resp["from"] = j.From.Format(time.RFC3339)
+        resp["to"] = j.To.Format(time.RFC3339)
+    }
+    if j.Stage != "" {
+        resp["stage"] = j.Stage
+    }
+    if j.RunID != 0 {
+        resp["run_id"] = j.RunID
+    }
+    if j.Counts != nil {
+        resp["counts"] = countsJSON(j.Counts)
+    }
+    if len(j.Sinks) > 0 {
+        resp["sinks"] = sinksJSON(j.Sinks)
+    }
+    if j.Error != "" {
+        resp["error"] = j.Error
+    }
+    if !j.StartedAt.IsZero() {
+        resp["started_at"] = j.StartedAt.Format(time.RFC3339)
+    }
+    if !j.FinishedAt.IsZero() {
+        resp["finished_at"] = j.FinishedAt.Format(time.RFC3339)
+    }
+    return resp
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    w.Header().Set("Content-Type", "application/json; charset=utf-8")
+    w.WriteHeader(status)
+    _ = json.NewEncoder(w).Encode(v)
+}
+
+// countsJSON renders per-entity sync counts for the JSON response.
+func countsJSON(counts map[string]domain.SyncCounts) map[string]any {
+    out := make(map[string]any, len(counts))
+    for entity, c := range counts {
+        out[entity] = map[string]int{
+            "fetched":   c.Fetched,
+            "inserted":  c.Inserted,
+            "updated":   c.Updated,
+            "unchanged": c.Unchanged,
+            "deleted":   c.Deleted,
+        }
+    }
+    return out
+}
+
+// sinksJSON renders per-sink results of a fan-out for the JSON response.
+func sinksJSON(sinks map[string]domain.SinkResult) map[string]any {
+    out := make(map[string]any, len(sinks))
+    for name, r := range sinks {
+        res := map[string]any{"counts": countsJSON(r.Counts)}
+        if r.Error != "" {
+            res["error"] = r.Error
+        }
+        out[name] = res
+    }
+    return out
+}
+
+// classifyError maps a sync error to an HTTP status and a stable error kind
+// for the JSON response. Toggl failures are upstream problems and map to
+// 502, except rate limiting which is passed through as 429.
+func classifyError(err error) (int, string) {
+    var (
+        authErr      *tg.AuthError
+        forbiddenErr *tg.ForbiddenError
+        rateErr      *tg.RateLimitError
+        serverErr    *tg.ServerError
+        netErr       *tg.NetworkError
+        decodeErr    *tg.DecodeError
+        truncErr     *tg.TruncatedError
+        apiErr       *tg.APIError
+    )
+    switch {
+    case errors.Is(err, ErrSyncRunning):
+        return http.StatusConflict, "conflict"
+    case errors.As(err, &authErr):
+        return http.StatusBadGateway, "toggl_auth"
+    case errors.As(err, &forbiddenErr):
+        return http.StatusBadGateway, "toggl_forbidden"
+    case errors.As(err, &rateErr):
+        return http.StatusTooManyRequests, "toggl_rate_limited"
+    case errors.As(err, &serverErr):
+        return http.StatusBadGateway, "toggl_unavailable"
+    case errors.As(err, &netErr):
+        return http.StatusBadGateway, "toggl_unreachable"
+    case errors.As(err, &decodeErr):
+        return http.StatusBadGateway, "toggl_decode"
+    case errors.As(err, &truncErr):
+        return http.StatusBadGateway, "toggl_truncated"
+    case errors.As(err, &apiErr):
+        return http.StatusBadGateway, "toggl_error"
+    case errors.Is(err, context.DeadlineExceeded):
+        return http.StatusGatewayTimeout, "timeout"
+    }
+    return http.StatusInternalServerError, "internal"
 }
 
 // loggingMiddleware provides basic request logging.
@@ -0,0 +1,300 @@
+package app
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "log/slog"
+    "maps"
+    "sync"
+    "time"
+
+    "toggl-scraper/internal/domain"
+    "toggl-scraper/internal/ports"
+    "toggl-scraper/internal/usecase"
+)
+
+// ErrJobQueueFull is returned by StartJob when too many jobs are waiting.
+var ErrJobQueueFull = errors.New("job queue full")
+
+// ErrJobDone is returned by CancelJob for a job that already finished.
+var ErrJobDone = errors.New("job already finished")
+
+// jobQueueSize bounds the number of jobs waiting to run.
+const jobQueueSize = 16
+
+// job is a queued or running job with the cancel func of its context.
+type job struct {
+    domain.Job
+    timeout time.Duration
+    cancel  context.CancelFunc
+}
+
+// jobQueue holds jobs until they finish and are saved to the store. Jobs
+// run one at a time, in the order they were started.
+type jobQueue struct {
+    store ports.JobStore
+    mu    sync.Mutex
+    byID  map[string]*job
+    queue chan *job
+}
+
+func newJobQueue(store ports.JobStore) *jobQueue {
+    return &jobQueue{
+        store: store,
+        byID:  make(map[string]*job),
+        queue: make(chan *job, jobQueueSize),
+    }
+}
+
+// StartJob queues a sync and returns the job without waiting for it. A
+// zero from and to runs an incremental sync; timeout, when positive,
+// limits the run once it starts.
+func (a *App) StartJob(from, to time.Time, timeout time.Duration) (domain.Job, error) {
+    id, err := newJobID()
+    if err != nil {
+        return domain.Job{}, err
+    }
+    j := &job{
+        Job: domain.Job{
+            ID:        id,
+            State:     domain.JobQueued,
+            Mode:      "window",
+            From:      from,
+            To:        to,
+            CreatedAt: time.Now().UTC(),
+        },
+        timeout: timeout,
+    }
+    if from.IsZero() && to.IsZero() {
+        j.Mode = "incremental"
+    }
+
+    q := a.jobs
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    select {
+    case q.queue <- j:
+    default:
+        return domain.Job{}, ErrJobQueueFull
+    }
+    q.byID[id] = j
+    a.log.Info("sync job queued", slog.String("job_id", id), slog.String("mode", j.Mode))
+    return snapshot(j), nil
+}
+
+// Job returns the job with the given ID, from memory while it is queued or
+// running and from the store once it finished.
+func (a *App) Job(ctx context.Context, id string) (domain.Job, bool, error) {
+    q := a.jobs
+    q.mu.Lock()
+    j, ok := q.byID[id]
+    var out domain.Job
+    if ok {
+        out = snapshot(j)
+    }
+    q.mu.Unlock()
+    if ok {
+        return out, true, nil
+    }
+    return q.store.LoadJob(ctx, id)
+}
+
+// CancelJob cancels a queued or running job. A queued job is finished
+// right away; a running one stops once its sync returns. ok is false if no
+// such job exists, and ErrJobDone is returned if it already finished.
+func (a *App) CancelJob(ctx context.Context, id string) (domain.Job, bool, error) {
+    q := a.jobs
+    q.mu.Lock()
+    j, ok := q.byID[id]
+    if !ok {
+        q.mu.Unlock()
+        stored, ok, err := q.store.LoadJob(ctx, id)
+        if err != nil || !ok {
+            return domain.Job{}, ok, err
+        }
+        return stored, true, ErrJobDone
+    }
+    if j.State.Done() {
+        out := snapshot(j)
+        q.mu.Unlock()
+        return out, true, ErrJobDone
+    }
+    if j.State == domain.JobRunning {
+        j.cancel()
+        out := snapshot(j)
+        q.mu.Unlock()
+        a.log.Info("sync job cancel requested", slog.String("job_id", id))
+        return out, true, nil
+    }
+    j.State = domain.JobCancelled
+    j.FinishedAt = time.Now().UTC()
+    out := snapshot(j)
+    q.mu.Unlock()
+    a.log.Info("sync job cancelled before start", slog.String("job_id", id))
+    a.saveJob(ctx, j)
+    return out, true, nil
+}
+
+// runJobs runs queued jobs one at a time until ctx is cancelled. Jobs
+// still queued then are stored as cancelled, so they can be polled after a
+// restart.
+func (a *App) runJobs(ctx context.Context) {
+    for {
+        select {
+        case <-ctx.Done():
+            for {
+                select {
+                case j := <-a.jobs.queue:
+                    a.cancelQueuedJob(ctx, j)
+                default:
+                    return
+                }
+            }
+        case j := <-a.jobs.queue:
+            if ctx.Err() != nil {
+                a.cancelQueuedJob(ctx, j)
+                continue
+            }
+            a.runJob(ctx, j)
+        }
+    }
+}
+
+// cancelQueuedJob finishes a job that never started because the server
+// is shutting down.
+func (a *App) cancelQueuedJob(ctx context.Context, j *job) {
+    q := a.jobs
+    q.mu.Lock()
+    if j.State.Done() {
+        // Cancelled while queued and already stored.
+        q.mu.Unlock()
+        return
+    }
+    j.State = domain.JobCancelled
+    j.FinishedAt = time.Now().UTC()
+    j.Error = "server shut down before the job started"
+    q.mu.Unlock()
+    a.log.Info("sync job cancelled at shutdown", slog.String("job_id", j.ID))
+    a.saveJob(ctx, j)
+}
+
+// runJob runs a single job, waiting for any other sync to finish first.
+func (a *App) runJob(ctx context.Context, j *job) {
+    q := a.jobs
+    // Checking for a cancel while queued, marking the job running and
+    // installing its cancel func happen under one lock, so CancelJob sees
+    // either a queued job or a running one it can cancel. The job counts
+    // as running while it waits for the run lock, so that DELETE can
+    // cancel the wait.
+    q.mu.Lock()
+    if j.State.Done() {
+        // Cancelled while queued.
+        q.mu.Unlock()
+        return
+    }
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    j.cancel = cancel
+    j.State = domain.JobRunning
+    j.StartedAt = time.Now().UTC()
+    q.mu.Unlock()
+    a.log.Info("sync job started", slog.String("job_id", j.ID))
+
+    ctx = usecase.WithProgress(ctx, func(stage string, counts map[string]domain.SyncCounts) {
+        q.mu.Lock()
+        defer q.mu.Unlock()
+        j.Stage = stage
+        j.Counts = counts
+    })
+    if j.timeout > 0 {
+        var cancelTimeout func()
+        ctx, cancelTimeout = context.WithTimeout(ctx, j.timeout)
+        defer cancelTimeout()
+    }
+
+    var (
+        run domain.SyncRun
+        err error
+    )
+    for {
+        if j.Mode == "incremental" {
+            run, err = a.RunIncremental(ctx, domain.TriggerHTTP)
+        } else {
+            run, err = a.RunOnce(ctx, domain.TriggerHTTP, j.From, j.To)
+        }
+        if !errors.Is(err, ErrSyncRunning) {
+            break
+        }
+        // Another sync holds the run lock; retry until it is released.
+        t := time.NewTimer(time.Second)
+        select {
+        case <-ctx.Done():
+            t.Stop()
+        case <-t.C:
+        }
+        if ctx.Err() != nil {
+            err = ctx.Err()
+            break
+        }
+    }
+
+    q.mu.Lock()
+    j.FinishedAt = time.Now().UTC()
+    if run.Counts != nil {
+        j.RunID = run.ID
+        j.From, j.To = run.From, run.To
+        j.Counts = run.Counts
+        j.Sinks = run.Sinks
+    }
+    switch {
+    case err == nil:
+        j.State = domain.JobOK
+    case errors.Is(err, context.Canceled):
+        j.State = domain.JobCancelled
+        j.Error = err.Error()
+    default:
+        j.State = domain.JobError
+        j.Error = err.Error()
+    }
+    q.mu.Unlock()
+    a.log.Info("sync job finished", slog.String("job_id", j.ID), slog.String("state", string(j.State)))
+    a.saveJob(ctx, j)
+}
+
+// saveJob stores a finished job and drops it from memory. It uses a fresh
+// context so that cancelled jobs are still stored; on failure the job is
+// kept in memory so it can still be polled.
+func (a *App) saveJob(ctx context.Context, j *job) {
+    q := a.jobs
+    q.mu.Lock()
+    out := snapshot(j)
+    q.mu.Unlock()
+
+    c, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+    defer cancel()
+    if err := q.store.SaveJob(c, out); err != nil {
+        a.log.Warn("failed to store sync job", slog.String("job_id", j.ID), slog.String("error", err.Error()))
+        return
+    }
+    q.mu.Lock()
+    delete(q.byID, j.ID)
+    q.mu.Unlock()
+}
+
+// snapshot copies a job for use outside the queue lock.
+func snapshot(j *job) domain.Job {
+    out := j.Job
+    out.Counts = maps.Clone(j.Counts)
+    out.Sinks = maps.Clone(j.Sinks)
+    return out
+}
+
+func newJobID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
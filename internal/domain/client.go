@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Client represents a Toggl client (customer) that projects belong to.
+type Client struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string
+	Archived    bool
+	At          time.Time // Last update timestamp from Toggl
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// DaySegment is the part of a time entry that falls on one local calendar
+// day.
+type DaySegment struct {
+	EntryID int64
+	Day     time.Time // local date at midnight UTC, e.g. 2025-08-01T00:00:00Z
+	Seconds int64
+}
+
+// DaySegments splits the entry at midnight in loc, one segment per day it
+// touches. Deleted and running entries have no segments. The entry's end is
+// Stop, or Start plus DurationSec when Stop is missing.
+func (e TimeEntry) DaySegments(loc *time.Location) []DaySegment {
+	if e.DeletedAt != nil || e.Running || e.IsRunning() {
+		return nil
+	}
+	start := e.Start.In(loc)
+	end := start.Add(time.Duration(e.DurationSec) * time.Second)
+	if e.Stop != nil {
+		end = e.Stop.In(loc)
+	}
+	var segments []DaySegment
+	for t := start; t.Before(end); {
+		y, m, d := t.Date()
+		next := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		segEnd := next
+		if end.Before(next) {
+			segEnd = end
+		}
+		// Seconds are measured from start so they always sum to the
+		// whole entry's length despite sub-second boundaries.
+		seconds := int64(segEnd.Sub(start)/time.Second) - int64(t.Sub(start)/time.Second)
+		segments = append(segments, DaySegment{
+			EntryID: e.ID,
+			Day:     time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+			Seconds: seconds,
+		})
+		t = next
+	}
+	return segments
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// JobState is the lifecycle state of an asynchronous sync job.
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobOK        JobState = "ok"
+	JobError     JobState = "error"
+	JobCancelled JobState = "cancelled"
+)
+
+// Done reports whether the job has finished, successfully or not.
+func (s JobState) Done() bool {
+	return s == JobOK || s == JobError || s == JobCancelled
+}
+
+// Job is a sync requested over HTTP and run in the background.
+type Job struct {
+	ID    string
+	State JobState
+	Mode  string // "window" or "incremental", like SyncRun.Mode
+	// From and To are the requested window; for incremental jobs they are
+	// filled in from the run once it starts.
+	From, To time.Time
+	// Stage names the step the sync is in while running, e.g. "projects"
+	// or "entries".
+	Stage      string
+	RunID      int64 // sync_runs ID, 0 if not recorded
+	Counts     map[string]SyncCounts
+	Sinks      map[string]SinkResult
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  time.Time // zero while queued
+	FinishedAt time.Time // zero until done
+}
@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// SyncTrigger identifies what started a sync run.
+type SyncTrigger string
+
+const (
+	TriggerCLI      SyncTrigger = "cli"
+	TriggerDaily    SyncTrigger = "daily"
+	TriggerInterval SyncTrigger = "interval"
+	TriggerHTTP     SyncTrigger = "http"
+)
+
+// SyncStatus is the lifecycle state of a sync run.
+type SyncStatus string
+
+const (
+	StatusRunning SyncStatus = "running"
+	StatusOK      SyncStatus = "ok"
+	StatusError   SyncStatus = "error"
+)
+
+// SyncCounts summarizes what a run did for one entity type.
+// Sinks report Inserted, Updated, Unchanged and Deleted; Fetched is filled
+// in by the use case from the Toggl response.
+type SyncCounts struct {
+	Fetched   int
+	Inserted  int
+	Updated   int
+	Unchanged int
+	Deleted   int
+}
+
+// Add accumulates o into c.
+func (c *SyncCounts) Add(o SyncCounts) {
+	c.Fetched += o.Fetched
+	c.Inserted += o.Inserted
+	c.Updated += o.Updated
+	c.Unchanged += o.Unchanged
+	c.Deleted += o.Deleted
+}
+
+// SinkResult is what one sink of a fan-out did during a run.
+type SinkResult struct {
+	Counts map[string]SyncCounts // keyed by entity, like SyncRun.Counts
+	Error  string                // first failure; empty if every write succeeded
+}
+
+// SyncRun records a single execution of the sync use case.
+type SyncRun struct {
+	ID         int64
+	Trigger    SyncTrigger
+	Mode       string    // "window" or "incremental"
+	From       time.Time // effective window; for incremental runs From is the watermark
+	To         time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Status     SyncStatus
+	Error      string
+	Counts     map[string]SyncCounts // keyed by entity: "entries", "projects", ...
+	// Sinks holds per-sink results when syncing to several sinks, keyed
+	// by sink name; nil for a single sink.
+	Sinks map[string]SinkResult
+}
+
+// Changed reports whether the run wrote anything, i.e. whether any entity
+// had rows inserted, updated or deleted.
+func (r SyncRun) Changed() bool {
+	for _, c := range r.Counts {
+		if c.Inserted+c.Updated+c.Deleted > 0 {
+			return true
+		}
+	}
+	return false
+}
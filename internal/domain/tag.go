@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Tag represents a Toggl workspace tag.
+type Tag struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string
+	At          time.Time // Last update timestamp from Toggl
+}
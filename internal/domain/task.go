@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// Task represents a Toggl task (paid plans) belonging to a project.
+type Task struct {
+	ID               int64
+	WorkspaceID      int64
+	ProjectID        int64
+	Name             string
+	EstimatedSeconds *int64
+	Active           bool
+	At               time.Time // Last update timestamp from Toggl
+}
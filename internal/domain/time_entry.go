@@ -7,9 +7,45 @@ type TimeEntry struct {
 	ID          int64
 	Description string
 	ProjectID   *int64
+	TaskID      *int64
 	WorkspaceID *int64
 	Tags        []string
+	TagIDs      []int64
 	Start       time.Time
 	Stop        *time.Time
 	DurationSec int64 // Negative means running in Toggl API semantics
+	Billable    bool
+	UserID      *int64
+	At          time.Time  // Last update timestamp from Toggl
+	DeletedAt   *time.Time // Toggl server_deleted_at; set for entries deleted server-side
+	CreatedWith string     // Client application that created the entry
+	// Running marks a timer still running at sync time. Stop is nil and
+	// DurationSec is provisional, measured up to the sync.
+	Running bool
+}
+
+// IsRunning reports whether Toggl returned the entry as a running timer,
+// with a negative DurationSec.
+func (e TimeEntry) IsRunning() bool {
+	return e.DurationSec < 0
+}
+
+// MarkRunning returns entries with running timers flagged and given a
+// provisional duration up to now. entries itself is left untouched.
+func MarkRunning(entries []TimeEntry, now time.Time) []TimeEntry {
+	out := entries
+	copied := false
+	for i, e := range entries {
+		if !e.IsRunning() {
+			continue
+		}
+		if !copied {
+			out = append([]TimeEntry(nil), entries...)
+			copied = true
+		}
+		out[i].Running = true
+		out[i].Stop = nil
+		out[i].DurationSec = max(0, int64(now.Sub(e.Start)/time.Second))
+	}
+	return out
 }
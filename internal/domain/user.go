@@ -0,0 +1,19 @@
+package domain
+
+// User represents a member of a Toggl workspace.
+type User struct {
+	ID          int64 // Toggl user ID, as referenced by TimeEntry.UserID
+	WorkspaceID int64
+	Name        string
+	Email       string
+	Active      bool
+	Admin       bool
+	GroupIDs    []int64
+}
+
+// Group represents a Toggl workspace group (team).
+type Group struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string
+}
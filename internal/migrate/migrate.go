@@ -4,6 +4,7 @@ import (
     "context"
     "database/sql"
     "embed"
+    "errors"
     "fmt"
     "io/fs"
     "log/slog"
@@ -14,17 +15,70 @@ import (
     "time"
 
     _ "github.com/go-sql-driver/mysql"
+    _ "github.com/jackc/pgx/v5/stdlib"
+    _ "modernc.org/sqlite"
 )
 
-//go:embed sql/*.sql
+//go:embed sql/mysql/*.sql sql/postgres/*.sql sql/sqlite/*.sql
 var migrationsFS embed.FS
 
-// Run applies pending migrations found under internal/migrate/sql.
-// Migrations must be named like 0001_description.sql and will be executed
-// in lexicographic order. The entire file is executed as a single statement
-// batch; the MySQL DSN should include multiStatements=true.
-func Run(ctx context.Context, dsn string, log *slog.Logger) error {
-    db, err := sql.Open("mysql", dsn)
+// Supported dialects for Run.
+const (
+    MySQL    = "mysql"
+    Postgres = "postgres"
+    SQLite   = "sqlite"
+)
+
+// dialect holds the per-database differences in running migrations.
+type dialect struct {
+    driver    string // database/sql driver name
+    dir       string // embedded directory holding the migrations
+    tableDDL  string
+    insertSQL string
+}
+
+var dialects = map[string]dialect{
+    MySQL: {
+        driver: "mysql",
+        dir:    "sql/mysql",
+        tableDDL: `CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT PRIMARY KEY,
+        applied_at DATETIME(6) NOT NULL
+    ) ENGINE=InnoDB;`,
+        insertSQL: "INSERT INTO schema_migrations(version, applied_at) VALUES(?, ?)",
+    },
+    Postgres: {
+        driver: "pgx",
+        dir:    "sql/postgres",
+        tableDDL: `CREATE TABLE IF NOT EXISTS schema_migrations (
+        version BIGINT PRIMARY KEY,
+        applied_at TIMESTAMPTZ NOT NULL
+    );`,
+        insertSQL: "INSERT INTO schema_migrations(version, applied_at) VALUES($1, $2)",
+    },
+    SQLite: {
+        driver: "sqlite",
+        dir:    "sql/sqlite",
+        tableDDL: `CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        applied_at DATETIME NOT NULL
+    );`,
+        insertSQL: "INSERT INTO schema_migrations(version, applied_at) VALUES(?, ?)",
+    },
+}
+
+// Run applies pending migrations for the given dialect (MySQL, Postgres or
+// SQLite) found under internal/migrate/sql/<dialect>. Migrations must be
+// named like 0001_description.sql and will be executed in lexicographic
+// order. The entire file is executed as a single statement batch; a MySQL
+// DSN should include multiStatements=true, and a SQLite DSN is the path of
+// the database file.
+func Run(ctx context.Context, dialectName, dsn string, log *slog.Logger) error {
+    d, ok := dialects[dialectName]
+    if !ok {
+        return fmt.Errorf("migrate: unsupported dialect %q", dialectName)
+    }
+    db, err := sql.Open(d.driver, dsn)
     if err != nil {
         return err
     }
@@ -36,11 +90,11 @@ func Run(ctx context.Context, dsn string, log *slog.Logger) error {
         return err
     }
 
-    if err := ensureMigrationsTable(ctx, db); err != nil {
+    if err := ensureMigrationsTable(ctx, db, d); err != nil {
         return err
     }
 
-    files, err := fs.Glob(migrationsFS, "sql/*.sql")
+    files, err := fs.Glob(migrationsFS, d.dir+"/*.sql")
     if err != nil {
         return err
     }
@@ -69,19 +123,70 @@ func Run(ctx context.Context, dsn string, log *slog.Logger) error {
         if _, err := db.ExecContext(ctx, string(b)); err != nil {
             return fmt.Errorf("applying %s: %w", base, err)
         }
-        if err := recordApplied(ctx, db, ver); err != nil {
+        if err := recordApplied(ctx, db, d, ver); err != nil {
             return err
         }
     }
     return nil
 }
 
-func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
-    const ddl = `CREATE TABLE IF NOT EXISTS schema_migrations (
-        version BIGINT PRIMARY KEY,
-        applied_at DATETIME(6) NOT NULL
-    ) ENGINE=InnoDB;`
-    _, err := db.ExecContext(ctx, ddl)
+// ErrSchemaBehind is returned by Check when a database lacks migrations
+// this build knows about.
+var ErrSchemaBehind = errors.New("migrate: database schema is behind")
+
+// Check verifies that every migration of the dialect has been applied to
+// the database at dsn without changing it. A SQLite database is opened
+// read-only, so a missing file is reported instead of created. Errors for
+// a database that was never migrated or is missing migrations wrap
+// ErrSchemaBehind.
+func Check(ctx context.Context, dialectName, dsn string) error {
+    d, ok := dialects[dialectName]
+    if !ok {
+        return fmt.Errorf("migrate: unsupported dialect %q", dialectName)
+    }
+    if dialectName == SQLite {
+        dsn = "file:" + dsn + "?mode=ro"
+    }
+    db, err := sql.Open(d.driver, dsn)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    c, cancel := context.WithTimeout(ctx, 5*time.Second)
+    defer cancel()
+    if err := db.PingContext(c); err != nil {
+        return err
+    }
+
+    files, err := fs.Glob(migrationsFS, d.dir+"/*.sql")
+    if err != nil {
+        return err
+    }
+    applied, err := loadApplied(ctx, db)
+    if err != nil {
+        return fmt.Errorf("%w: no migrations applied (%v)", ErrSchemaBehind, err)
+    }
+    var missing []string
+    for _, f := range files {
+        base := filepath.Base(f)
+        ver, err := parseVersion(base)
+        if err != nil {
+            return fmt.Errorf("invalid migration filename %q: %w", base, err)
+        }
+        if !applied[ver] {
+            missing = append(missing, base)
+        }
+    }
+    if len(missing) > 0 {
+        sort.Strings(missing)
+        return fmt.Errorf("%w: %d migration(s) pending: %s", ErrSchemaBehind, len(missing), strings.Join(missing, ", "))
+    }
+    return nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *sql.DB, d dialect) error {
+    _, err := db.ExecContext(ctx, d.tableDDL)
     return err
 }
 
@@ -102,8 +207,8 @@ func loadApplied(ctx context.Context, db *sql.DB) (map[int]bool, error) {
     return m, rows.Err()
 }
 
-func recordApplied(ctx context.Context, db *sql.DB, version int) error {
-    _, err := db.ExecContext(ctx, "INSERT INTO schema_migrations(version, applied_at) VALUES(?, ?)", version, time.Now().UTC())
+func recordApplied(ctx context.Context, db *sql.DB, d dialect, version int) error {
+    _, err := db.ExecContext(ctx, d.insertSQL, version, time.Now().UTC())
     return err
 }
 
@@ -0,0 +1,8 @@
+-- Store Toggl clients so toggl_projects.client_id can be resolved to a name
+CREATE TABLE IF NOT EXISTS toggl_clients (
+  id BIGINT PRIMARY KEY,
+  workspace_id BIGINT NOT NULL,
+  name TEXT NOT NULL,
+  archived TINYINT(1) NOT NULL,
+  at DATETIME(6) NOT NULL
+) ENGINE=InnoDB;
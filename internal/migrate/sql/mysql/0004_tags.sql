@@ -0,0 +1,15 @@
+-- Store Toggl workspace tags as first-class entities
+CREATE TABLE IF NOT EXISTS toggl_tags (
+  id BIGINT PRIMARY KEY,
+  workspace_id BIGINT NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  at DATETIME(6) NOT NULL
+) ENGINE=InnoDB;
+
+-- Normalized entry/tag membership, maintained by the sink on every upsert
+CREATE TABLE IF NOT EXISTS toggl_time_entry_tags (
+  entry_id BIGINT NOT NULL,
+  tag_id BIGINT NOT NULL,
+  PRIMARY KEY (entry_id, tag_id),
+  KEY idx_time_entry_tags_tag (tag_id)
+) ENGINE=InnoDB;
@@ -0,0 +1,16 @@
+-- Store Toggl tasks for estimate-vs-actual reporting
+CREATE TABLE IF NOT EXISTS toggl_tasks (
+  id BIGINT PRIMARY KEY,
+  workspace_id BIGINT NOT NULL,
+  project_id BIGINT NOT NULL,
+  name TEXT NOT NULL,
+  estimated_seconds BIGINT NULL,
+  active TINYINT(1) NOT NULL,
+  at DATETIME(6) NOT NULL,
+  KEY idx_tasks_project (project_id)
+) ENGINE=InnoDB;
+
+-- Link time entries to tasks
+ALTER TABLE toggl_time_entries
+  ADD COLUMN task_id BIGINT NULL AFTER project_id,
+  ADD KEY idx_time_entries_task (task_id);
@@ -0,0 +1,9 @@
+-- Capture billable flag, owner and server-side timestamps from Toggl v9
+ALTER TABLE toggl_time_entries
+  ADD COLUMN billable TINYINT(1) NOT NULL DEFAULT 0,
+  ADD COLUMN user_id BIGINT NULL,
+  ADD COLUMN at DATETIME(6) NULL,
+  ADD COLUMN server_deleted_at DATETIME(6) NULL,
+  ADD COLUMN created_with VARCHAR(255) NULL,
+  ADD KEY idx_time_entries_user (user_id),
+  ADD KEY idx_time_entries_at (at);
@@ -0,0 +1,6 @@
+-- Incremental sync position: the latest Toggl `at` seen per workspace
+CREATE TABLE IF NOT EXISTS sync_watermarks (
+  workspace_id BIGINT PRIMARY KEY,
+  entries_at DATETIME(6) NOT NULL,
+  updated_at DATETIME(6) NOT NULL
+) ENGINE=InnoDB;
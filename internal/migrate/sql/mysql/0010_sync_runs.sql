@@ -0,0 +1,25 @@
+-- One row per sync run, for freshness and audit dashboards
+CREATE TABLE IF NOT EXISTS sync_runs (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  trigger_source VARCHAR(16) NOT NULL,
+  mode VARCHAR(16) NOT NULL,
+  window_from DATETIME(6) NOT NULL,
+  window_to DATETIME(6) NOT NULL,
+  started_at DATETIME(6) NOT NULL,
+  finished_at DATETIME(6) NULL,
+  status VARCHAR(16) NOT NULL,
+  error TEXT NULL,
+  KEY idx_sync_runs_started (started_at)
+) ENGINE=InnoDB;
+
+-- Per-entity counts for each run
+CREATE TABLE IF NOT EXISTS sync_run_counts (
+  run_id BIGINT NOT NULL,
+  entity VARCHAR(32) NOT NULL,
+  fetched INT NOT NULL,
+  inserted INT NOT NULL,
+  updated INT NOT NULL,
+  unchanged INT NOT NULL,
+  deleted INT NOT NULL,
+  PRIMARY KEY (run_id, entity)
+) ENGINE=InnoDB;
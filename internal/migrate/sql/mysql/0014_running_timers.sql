@@ -0,0 +1,34 @@
+-- Running timers are stored with is_running set and a provisional duration up
+-- to the sync; a later sync reconciles them to their final stop time.
+ALTER TABLE toggl_time_entries
+  ADD COLUMN is_running TINYINT(1) NOT NULL DEFAULT 0;
+
+-- Timers stored before this migration kept Toggl's negative duration.
+UPDATE toggl_time_entries
+SET is_running = 1, duration_sec = GREATEST(TIMESTAMPDIFF(SECOND, start, UTC_TIMESTAMP()), 0)
+WHERE duration_sec < 0;
+
+-- Pick up the new column.
+CREATE OR REPLACE VIEW toggl_time_entries_active AS
+SELECT * FROM toggl_time_entries WHERE deleted_at IS NULL;
+
+-- Running entries count up to the last sync.
+CREATE OR REPLACE VIEW toggl_report_entries AS
+SELECT e.id, e.workspace_id, e.user_id, u.name AS user_name,
+  e.project_id, p.name AS project_name, p.client_id, c.name AS client_name,
+  e.description, e.tags, e.billable, e.start, e.stop, e.duration_sec,
+  e.duration_sec / 3600.0 AS hours, e.is_running
+FROM toggl_time_entries_active e
+LEFT JOIN toggl_projects p ON p.id = e.project_id
+LEFT JOIN toggl_clients c ON c.id = p.client_id
+LEFT JOIN toggl_users u ON u.id = e.user_id;
+
+-- Totals cover finished entries only, like the rollups.
+CREATE OR REPLACE VIEW toggl_report_tag_totals AS
+SELECT t.id AS tag_id, t.name AS tag_name, COUNT(*) AS entries,
+  SUM(e.duration_sec) AS seconds, SUM(e.duration_sec) / 3600.0 AS hours
+FROM toggl_time_entry_tags et
+JOIN toggl_tags t ON t.id = et.tag_id
+JOIN toggl_time_entries_active e ON e.id = et.entry_id
+WHERE NOT e.is_running
+GROUP BY t.id, t.name;
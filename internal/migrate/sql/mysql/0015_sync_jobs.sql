@@ -0,0 +1,18 @@
+-- Finished sync jobs started over HTTP (POST /jobs), kept for GET /jobs/{id}
+-- after a restart. counts and sinks hold the per-entity and per-sink results
+-- as JSON; the same counts are in sync_run_counts under run_id.
+CREATE TABLE IF NOT EXISTS sync_jobs (
+  id VARCHAR(64) PRIMARY KEY,
+  state VARCHAR(16) NOT NULL,
+  mode VARCHAR(16) NOT NULL,
+  window_from DATETIME(6) NULL,
+  window_to DATETIME(6) NULL,
+  run_id BIGINT NULL,
+  counts TEXT NULL,
+  sinks TEXT NULL,
+  error TEXT NULL,
+  created_at DATETIME(6) NOT NULL,
+  started_at DATETIME(6) NULL,
+  finished_at DATETIME(6) NULL,
+  KEY idx_sync_jobs_created (created_at)
+) ENGINE=InnoDB;
@@ -0,0 +1,11 @@
+-- Create initial schema for Toggl time entries
+CREATE TABLE IF NOT EXISTS toggl_time_entries (
+  id BIGINT PRIMARY KEY,
+  description TEXT,
+  project_id BIGINT NULL,
+  workspace_id BIGINT NULL,
+  tags JSONB,
+  start TIMESTAMPTZ NOT NULL,
+  stop TIMESTAMPTZ NULL,
+  duration_sec BIGINT NOT NULL
+);
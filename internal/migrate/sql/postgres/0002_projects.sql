@@ -0,0 +1,11 @@
+-- Store Toggl projects for reference data
+CREATE TABLE IF NOT EXISTS toggl_projects (
+  id BIGINT PRIMARY KEY,
+  workspace_id BIGINT NOT NULL,
+  name TEXT NOT NULL,
+  active BOOLEAN NOT NULL,
+  is_private BOOLEAN NOT NULL,
+  color VARCHAR(32) NOT NULL,
+  client_id BIGINT NULL,
+  at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Store Toggl tasks for estimate-vs-actual reporting
+CREATE TABLE IF NOT EXISTS toggl_tasks (
+  id BIGINT PRIMARY KEY,
+  workspace_id BIGINT NOT NULL,
+  project_id BIGINT NOT NULL,
+  name TEXT NOT NULL,
+  estimated_seconds BIGINT NULL,
+  active BOOLEAN NOT NULL,
+  at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON toggl_tasks (project_id);
+
+-- Link time entries to tasks
+ALTER TABLE toggl_time_entries ADD COLUMN IF NOT EXISTS task_id BIGINT NULL;
+CREATE INDEX IF NOT EXISTS idx_time_entries_task ON toggl_time_entries (task_id);
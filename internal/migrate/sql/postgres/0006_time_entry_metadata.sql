@@ -0,0 +1,9 @@
+-- Capture billable flag, owner and server-side timestamps from Toggl v9
+ALTER TABLE toggl_time_entries
+  ADD COLUMN IF NOT EXISTS billable BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS user_id BIGINT NULL,
+  ADD COLUMN IF NOT EXISTS at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS server_deleted_at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS created_with VARCHAR(255) NULL;
+CREATE INDEX IF NOT EXISTS idx_time_entries_user ON toggl_time_entries (user_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_at ON toggl_time_entries (at);
@@ -0,0 +1,25 @@
+-- One row per sync run, for freshness and audit dashboards
+CREATE TABLE IF NOT EXISTS sync_runs (
+  id BIGSERIAL PRIMARY KEY,
+  trigger_source VARCHAR(16) NOT NULL,
+  mode VARCHAR(16) NOT NULL,
+  window_from TIMESTAMPTZ NOT NULL,
+  window_to TIMESTAMPTZ NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NULL,
+  status VARCHAR(16) NOT NULL,
+  error TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started ON sync_runs (started_at);
+
+-- Per-entity counts for each run
+CREATE TABLE IF NOT EXISTS sync_run_counts (
+  run_id BIGINT NOT NULL,
+  entity VARCHAR(32) NOT NULL,
+  fetched INT NOT NULL,
+  inserted INT NOT NULL,
+  updated INT NOT NULL,
+  unchanged INT NOT NULL,
+  deleted INT NOT NULL,
+  PRIMARY KEY (run_id, entity)
+);
@@ -0,0 +1,10 @@
+-- Time entries split at local midnight (SYNC_TZ), one row per entry per day
+-- with the seconds worked on that day. Maintained by the sink whenever an
+-- entry is upserted or deleted; deleted and running entries have no rows.
+CREATE TABLE IF NOT EXISTS toggl_daily_entry_segments (
+  entry_id BIGINT NOT NULL,
+  day DATE NOT NULL,
+  seconds BIGINT NOT NULL,
+  PRIMARY KEY (entry_id, day)
+);
+CREATE INDEX IF NOT EXISTS idx_daily_entry_segments_day ON toggl_daily_entry_segments (day);
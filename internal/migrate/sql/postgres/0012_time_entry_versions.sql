@@ -0,0 +1,28 @@
+-- Append-only history of time entries. The sink opens a new version whenever
+-- an upsert changes description, project, tags, start, stop or duration, and
+-- closes the previous one by setting valid_to; deleting an entry closes its
+-- open version. The version valid at time T has
+-- valid_from <= T AND (valid_to IS NULL OR valid_to > T).
+CREATE TABLE IF NOT EXISTS toggl_time_entry_versions (
+  version_id BIGSERIAL PRIMARY KEY,
+  entry_id BIGINT NOT NULL,
+  description TEXT,
+  project_id BIGINT NULL,
+  tags JSONB,
+  start TIMESTAMPTZ NOT NULL,
+  stop TIMESTAMPTZ NULL,
+  duration_sec BIGINT NOT NULL,
+  at TIMESTAMPTZ NULL,
+  valid_from TIMESTAMPTZ NOT NULL,
+  valid_to TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_time_entry_versions_entry ON toggl_time_entry_versions (entry_id, valid_to);
+CREATE INDEX IF NOT EXISTS idx_time_entry_versions_valid ON toggl_time_entry_versions (valid_from, valid_to);
+
+-- Existing live entries start with one open version, valid since their last
+-- edit in Toggl.
+INSERT INTO toggl_time_entry_versions
+  (entry_id, description, project_id, tags, start, stop, duration_sec, at, valid_from)
+SELECT id, description, project_id, tags, start, stop, duration_sec, at, COALESCE(at, start)
+FROM toggl_time_entries
+WHERE deleted_at IS NULL;
@@ -0,0 +1,91 @@
+-- Rollups of daily entry segments per project. The sink refreshes the
+-- periods each SyncEntries batch and soft-delete touches. project_id 0
+-- means "no project".
+
+CREATE TABLE IF NOT EXISTS toggl_rollup_daily (
+  day DATE NOT NULL,
+  project_id BIGINT NOT NULL,
+  seconds BIGINT NOT NULL,
+  entries BIGINT NOT NULL,
+  PRIMARY KEY (day, project_id)
+);
+
+CREATE TABLE IF NOT EXISTS toggl_rollup_weekly (
+  week_start DATE NOT NULL, -- Monday
+  project_id BIGINT NOT NULL,
+  seconds BIGINT NOT NULL,
+  entries BIGINT NOT NULL,
+  PRIMARY KEY (week_start, project_id)
+);
+
+CREATE TABLE IF NOT EXISTS toggl_rollup_monthly (
+  month_start DATE NOT NULL,
+  project_id BIGINT NOT NULL,
+  seconds BIGINT NOT NULL,
+  entries BIGINT NOT NULL,
+  PRIMARY KEY (month_start, project_id)
+);
+
+-- Backfill from the segments already stored, so existing history shows up
+-- in the reports right after upgrading.
+INSERT INTO toggl_rollup_daily (day, project_id, seconds, entries)
+SELECT s.day, COALESCE(e.project_id, 0), SUM(s.seconds), COUNT(DISTINCT s.entry_id)
+FROM toggl_daily_entry_segments s
+JOIN toggl_time_entries e ON e.id = s.entry_id AND e.deleted_at IS NULL
+GROUP BY 1, 2;
+
+INSERT INTO toggl_rollup_weekly (week_start, project_id, seconds, entries)
+SELECT CAST(date_trunc('week', s.day) AS DATE), COALESCE(e.project_id, 0), SUM(s.seconds), COUNT(DISTINCT s.entry_id)
+FROM toggl_daily_entry_segments s
+JOIN toggl_time_entries e ON e.id = s.entry_id AND e.deleted_at IS NULL
+GROUP BY 1, 2;
+
+INSERT INTO toggl_rollup_monthly (month_start, project_id, seconds, entries)
+SELECT CAST(date_trunc('month', s.day) AS DATE), COALESCE(e.project_id, 0), SUM(s.seconds), COUNT(DISTINCT s.entry_id)
+FROM toggl_daily_entry_segments s
+JOIN toggl_time_entries e ON e.id = s.entry_id AND e.deleted_at IS NULL
+GROUP BY 1, 2;
+
+-- Live entries with project, client and user names, and hours.
+CREATE OR REPLACE VIEW toggl_report_entries AS
+SELECT e.id, e.workspace_id, e.user_id, u.name AS user_name,
+  e.project_id, p.name AS project_name, p.client_id, c.name AS client_name,
+  e.description, e.tags, e.billable, e.start, e.stop, e.duration_sec,
+  e.duration_sec / 3600.0 AS hours
+FROM toggl_time_entries_active e
+LEFT JOIN toggl_projects p ON p.id = e.project_id
+LEFT JOIN toggl_clients c ON c.id = p.client_id
+LEFT JOIN toggl_users u ON u.id = e.user_id;
+
+-- Hours per project and day, from toggl_rollup_daily.
+CREATE OR REPLACE VIEW toggl_report_project_daily AS
+SELECT r.day, NULLIF(r.project_id, 0) AS project_id, p.name AS project_name, c.name AS client_name,
+  r.entries, r.seconds, r.seconds / 3600.0 AS hours
+FROM toggl_rollup_daily r
+LEFT JOIN toggl_projects p ON p.id = r.project_id
+LEFT JOIN toggl_clients c ON c.id = p.client_id;
+
+-- Hours per project and week, from toggl_rollup_weekly.
+CREATE OR REPLACE VIEW toggl_report_project_weekly AS
+SELECT r.week_start, NULLIF(r.project_id, 0) AS project_id, p.name AS project_name, c.name AS client_name,
+  r.entries, r.seconds, r.seconds / 3600.0 AS hours
+FROM toggl_rollup_weekly r
+LEFT JOIN toggl_projects p ON p.id = r.project_id
+LEFT JOIN toggl_clients c ON c.id = p.client_id;
+
+-- Hours per project and month, from toggl_rollup_monthly.
+CREATE OR REPLACE VIEW toggl_report_project_monthly AS
+SELECT r.month_start, NULLIF(r.project_id, 0) AS project_id, p.name AS project_name, c.name AS client_name,
+  r.entries, r.seconds, r.seconds / 3600.0 AS hours
+FROM toggl_rollup_monthly r
+LEFT JOIN toggl_projects p ON p.id = r.project_id
+LEFT JOIN toggl_clients c ON c.id = p.client_id;
+
+-- Totals per tag over live entries.
+CREATE OR REPLACE VIEW toggl_report_tag_totals AS
+SELECT t.id AS tag_id, t.name AS tag_name, COUNT(*) AS entries,
+  SUM(e.duration_sec) AS seconds, SUM(e.duration_sec) / 3600.0 AS hours
+FROM toggl_time_entry_tags et
+JOIN toggl_tags t ON t.id = et.tag_id
+JOIN toggl_time_entries_active e ON e.id = et.entry_id
+GROUP BY t.id, t.name;
@@ -0,0 +1,18 @@
+-- Finished sync jobs started over HTTP (POST /jobs), kept for GET /jobs/{id}
+-- after a restart. counts and sinks hold the per-entity and per-sink results
+-- as JSON; the same counts are in sync_run_counts under run_id.
+CREATE TABLE IF NOT EXISTS sync_jobs (
+  id VARCHAR(64) PRIMARY KEY,
+  state VARCHAR(16) NOT NULL,
+  mode VARCHAR(16) NOT NULL,
+  window_from TIMESTAMPTZ NULL,
+  window_to TIMESTAMPTZ NULL,
+  run_id BIGINT NULL,
+  counts JSONB NULL,
+  sinks JSONB NULL,
+  error TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  started_at TIMESTAMPTZ NULL,
+  finished_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_created ON sync_jobs (created_at);
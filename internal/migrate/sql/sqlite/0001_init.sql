@@ -0,0 +1,11 @@
+-- Create initial schema for Toggl time entries
+CREATE TABLE IF NOT EXISTS toggl_time_entries (
+  id INTEGER PRIMARY KEY,
+  description TEXT,
+  project_id INTEGER NULL,
+  workspace_id INTEGER NULL,
+  tags TEXT,
+  start DATETIME NOT NULL,
+  stop DATETIME NULL,
+  duration_sec INTEGER NOT NULL
+);
@@ -0,0 +1,11 @@
+-- Store Toggl projects for reference data
+CREATE TABLE IF NOT EXISTS toggl_projects (
+  id INTEGER PRIMARY KEY,
+  workspace_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  active BOOLEAN NOT NULL,
+  is_private BOOLEAN NOT NULL,
+  color TEXT NOT NULL,
+  client_id INTEGER NULL,
+  at DATETIME NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Store Toggl clients so toggl_projects.client_id can be resolved to a name
+CREATE TABLE IF NOT EXISTS toggl_clients (
+  id INTEGER PRIMARY KEY,
+  workspace_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  archived BOOLEAN NOT NULL,
+  at DATETIME NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Store Toggl workspace tags as first-class entities
+CREATE TABLE IF NOT EXISTS toggl_tags (
+  id INTEGER PRIMARY KEY,
+  workspace_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  at DATETIME NOT NULL
+);
+
+-- Normalized entry/tag membership, maintained by the sink on every upsert
+CREATE TABLE IF NOT EXISTS toggl_time_entry_tags (
+  entry_id INTEGER NOT NULL,
+  tag_id INTEGER NOT NULL,
+  PRIMARY KEY (entry_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_time_entry_tags_tag ON toggl_time_entry_tags (tag_id);
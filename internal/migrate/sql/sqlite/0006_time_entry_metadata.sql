@@ -0,0 +1,8 @@
+-- Capture billable flag, owner and server-side timestamps from Toggl v9
+ALTER TABLE toggl_time_entries ADD COLUMN billable BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE toggl_time_entries ADD COLUMN user_id INTEGER NULL;
+ALTER TABLE toggl_time_entries ADD COLUMN at DATETIME NULL;
+ALTER TABLE toggl_time_entries ADD COLUMN server_deleted_at DATETIME NULL;
+ALTER TABLE toggl_time_entries ADD COLUMN created_with TEXT NULL;
+CREATE INDEX IF NOT EXISTS idx_time_entries_user ON toggl_time_entries (user_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_at ON toggl_time_entries (at);
@@ -0,0 +1,24 @@
+-- Store Toggl workspace users; toggl_time_entries.user_id joins on id
+CREATE TABLE IF NOT EXISTS toggl_users (
+  id INTEGER PRIMARY KEY,
+  workspace_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  email TEXT NOT NULL,
+  active BOOLEAN NOT NULL,
+  admin BOOLEAN NOT NULL
+);
+
+-- Store Toggl workspace groups
+CREATE TABLE IF NOT EXISTS toggl_groups (
+  id INTEGER PRIMARY KEY,
+  workspace_id INTEGER NOT NULL,
+  name TEXT NOT NULL
+);
+
+-- Group membership, maintained by the sink from each user's group IDs
+CREATE TABLE IF NOT EXISTS toggl_group_members (
+  group_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_group_members_user ON toggl_group_members (user_id);
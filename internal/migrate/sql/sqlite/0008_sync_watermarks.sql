@@ -0,0 +1,6 @@
+-- Incremental sync position: the latest Toggl `at` seen per workspace
+CREATE TABLE IF NOT EXISTS sync_watermarks (
+  workspace_id INTEGER PRIMARY KEY,
+  entries_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
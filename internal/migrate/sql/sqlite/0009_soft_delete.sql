@@ -0,0 +1,9 @@
+-- Soft-delete marker for entries deleted in Toggl. Set from server_deleted_at
+-- on `since` queries, or to the sync time when an entry disappears from a
+-- fully fetched window.
+ALTER TABLE toggl_time_entries ADD COLUMN deleted_at DATETIME NULL;
+CREATE INDEX IF NOT EXISTS idx_time_entries_start ON toggl_time_entries (start);
+
+-- Live entries only; point reports at this view instead of the table.
+CREATE VIEW IF NOT EXISTS toggl_time_entries_active AS
+SELECT * FROM toggl_time_entries WHERE deleted_at IS NULL;
@@ -0,0 +1,25 @@
+-- One row per sync run, for freshness and audit dashboards
+CREATE TABLE IF NOT EXISTS sync_runs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  trigger_source TEXT NOT NULL,
+  mode TEXT NOT NULL,
+  window_from DATETIME NOT NULL,
+  window_to DATETIME NOT NULL,
+  started_at DATETIME NOT NULL,
+  finished_at DATETIME NULL,
+  status TEXT NOT NULL,
+  error TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started ON sync_runs (started_at);
+
+-- Per-entity counts for each run
+CREATE TABLE IF NOT EXISTS sync_run_counts (
+  run_id INTEGER NOT NULL,
+  entity TEXT NOT NULL,
+  fetched INTEGER NOT NULL,
+  inserted INTEGER NOT NULL,
+  updated INTEGER NOT NULL,
+  unchanged INTEGER NOT NULL,
+  deleted INTEGER NOT NULL,
+  PRIMARY KEY (run_id, entity)
+);
@@ -0,0 +1,18 @@
+-- Finished sync jobs started over HTTP (POST /jobs), kept for GET /jobs/{id}
+-- after a restart. counts and sinks hold the per-entity and per-sink results
+-- as JSON; the same counts are in sync_run_counts under run_id.
+CREATE TABLE IF NOT EXISTS sync_jobs (
+  id TEXT PRIMARY KEY,
+  state TEXT NOT NULL,
+  mode TEXT NOT NULL,
+  window_from DATETIME NULL,
+  window_to DATETIME NULL,
+  run_id INTEGER NULL,
+  counts TEXT NULL,
+  sinks TEXT NULL,
+  error TEXT NULL,
+  created_at DATETIME NOT NULL,
+  started_at DATETIME NULL,
+  finished_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_created ON sync_jobs (created_at);
@@ -2,21 +2,95 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"toggl-scraper/internal/domain"
 )
 
+// ErrSinceUnsupported is returned by TogglClient.ListTimeEntriesSince when
+// the source cannot list entries by modification time.
+var ErrSinceUnsupported = errors.New("toggl: listing entries by modification time is not supported")
+
 // TogglClient defines methods to fetch time entries from Toggl.
 type TogglClient interface {
 	ListTimeEntries(ctx context.Context, from, to time.Time) ([]domain.TimeEntry, error)
+	// ListTimeEntriesSince returns entries modified at or after since,
+	// including entries deleted server-side (DeletedAt set). Clients that
+	// cannot filter by modification time return ErrSinceUnsupported.
+	ListTimeEntriesSince(ctx context.Context, since time.Time) ([]domain.TimeEntry, error)
 	ListProjects(ctx context.Context) ([]domain.Project, error)
+	ListClients(ctx context.Context) ([]domain.Client, error)
+	ListTags(ctx context.Context) ([]domain.Tag, error)
+	ListTasks(ctx context.Context) ([]domain.Task, error)
+	ListUsers(ctx context.Context) ([]domain.User, error)
+	ListGroups(ctx context.Context) ([]domain.Group, error)
 }
 
 // Sink receives entries and persists them to a target system.
 // In this project, the primary target is Metabase-adjacent storage, but the
-// interface is intentionally generic to support other sinks.
+// interface is intentionally generic to support other sinks. Sync methods
+// report how many rows were inserted, updated or left unchanged.
 type Sink interface {
-	SyncEntries(ctx context.Context, entries []domain.TimeEntry) error
-	SyncProjects(ctx context.Context, projects []domain.Project) error
+	SyncEntries(ctx context.Context, entries []domain.TimeEntry) (domain.SyncCounts, error)
+	// MarkMissingDeleted soft-deletes stored entries that started in
+	// [from, to) but are absent from present, the complete set of IDs
+	// Toggl returned for that window. workspaceID 0 means unscoped.
+	// It returns the number of rows soft-deleted.
+	MarkMissingDeleted(ctx context.Context, from, to time.Time, workspaceID int64, present []int64) (int, error)
+	SyncProjects(ctx context.Context, projects []domain.Project) (domain.SyncCounts, error)
+	SyncClients(ctx context.Context, clients []domain.Client) (domain.SyncCounts, error)
+	SyncTags(ctx context.Context, tags []domain.Tag) (domain.SyncCounts, error)
+	SyncTasks(ctx context.Context, tasks []domain.Task) (domain.SyncCounts, error)
+	SyncUsers(ctx context.Context, users []domain.User) (domain.SyncCounts, error)
+	SyncGroups(ctx context.Context, groups []domain.Group) (domain.SyncCounts, error)
+}
+
+// WatermarkStore persists the incremental sync position per workspace.
+type WatermarkStore interface {
+	// LoadWatermark returns the stored watermark; ok is false if none exists.
+	LoadWatermark(ctx context.Context, workspaceID int64) (mark time.Time, ok bool, err error)
+	SaveWatermark(ctx context.Context, workspaceID int64, mark time.Time) error
+}
+
+// RunRecorder persists the history of sync runs.
+type RunRecorder interface {
+	// StartRun records a run in progress and returns its ID.
+	StartRun(ctx context.Context, run domain.SyncRun) (int64, error)
+	// FinishRun records the outcome of a run previously started.
+	FinishRun(ctx context.Context, run domain.SyncRun) error
+}
+
+// JobStore persists finished sync jobs so their status survives restarts.
+type JobStore interface {
+	SaveJob(ctx context.Context, job domain.Job) error
+	// LoadJob returns the stored job; ok is false if none exists.
+	LoadJob(ctx context.Context, id string) (job domain.Job, ok bool, err error)
+}
+
+// SinkReporter is implemented by sinks that fan out to several targets.
+type SinkReporter interface {
+	// TakeResults returns the per-target results gathered since the last
+	// call, keyed by target name, and resets them.
+	TakeResults() map[string]domain.SinkResult
+}
+
+// EntryReader reads stored time entries back from a sink.
+type EntryReader interface {
+	// ListEntries returns live entries that started in [from, to), ordered
+	// by start time.
+	ListEntries(ctx context.Context, from, to time.Time) ([]domain.TimeEntry, error)
+}
+
+// RunningEntryReader lists timers a sink stored as running.
+type RunningEntryReader interface {
+	// ListRunningEntries returns live entries stored with Running set.
+	ListRunningEntries(ctx context.Context) ([]domain.TimeEntry, error)
+}
+
+// EntryHistoryReader reads time entries as a sink stored them in the past.
+type EntryHistoryReader interface {
+	// ListEntriesAsOf returns entries that started in [from, to) as they
+	// were at asOf, ordered by start time.
+	ListEntriesAsOf(ctx context.Context, asOf, from, to time.Time) ([]domain.TimeEntry, error)
 }
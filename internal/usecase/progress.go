@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+	"maps"
+
+	"toggl-scraper/internal/domain"
+)
+
+// ProgressFunc is told the stage a run enters, e.g. "projects", "entries",
+// "deletions" or "running", with a copy of the counts so far.
+type ProgressFunc func(stage string, counts map[string]domain.SyncCounts)
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes runs started with it report
+// their progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func reportProgress(ctx context.Context, stage string, run *domain.SyncRun) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(stage, maps.Clone(run.Counts))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	"toggl-scraper/internal/domain"
 	"toggl-scraper/internal/ports"
 )
 
@@ -14,44 +15,358 @@ type SyncUseCase struct {
 	Log   *slog.Logger
 	Toggl ports.TogglClient
 	Sink  ports.Sink
+	// Watermarks persists the incremental sync position. Only required
+	// by RunIncremental.
+	Watermarks ports.WatermarkStore
+	// Runs records the history of sync runs. Optional.
+	Runs ports.RunRecorder
+	// Running lists timers stored as running, so they are reconciled once
+	// they stop. Optional.
+	Running ports.RunningEntryReader
+	// WorkspaceID keys the watermark; 0 when no workspace is configured.
+	WorkspaceID int64
+	// Lookback is the window RunIncremental re-fetches when the client
+	// cannot list entries by modification time; default 7 days.
+	Lookback time.Duration
 }
 
-func (uc *SyncUseCase) Run(ctx context.Context, from, to time.Time) error {
+// defaultLookback is used when SyncUseCase.Lookback is unset.
+const defaultLookback = 7 * 24 * time.Hour
+
+// Run syncs reference data and all time entries starting in [from, to).
+// An explicit window never moves the incremental watermark. The returned
+// run summarizes per-entity counts, also when err is non-nil.
+func (uc *SyncUseCase) Run(ctx context.Context, trigger domain.SyncTrigger, from, to time.Time) (domain.SyncRun, error) {
 	if uc.Toggl == nil || uc.Sink == nil {
-		return errors.New("usecase not initialized: missing dependencies")
+		return domain.SyncRun{}, errors.New("usecase not initialized: missing dependencies")
+	}
+	run := uc.startRun(ctx, trigger, "window", from, to)
+	err := uc.runWindow(ctx, run, from, to)
+	uc.finishRun(ctx, run, err)
+	return *run, err
+}
+
+func (uc *SyncUseCase) runWindow(ctx context.Context, run *domain.SyncRun, from, to time.Time) error {
+	if err := uc.syncReferenceData(ctx, run); err != nil {
+		return err
 	}
-	uc.Log.Info("fetching projects")
 
-	projects, err := uc.Toggl.ListProjects(ctx)
+	uc.Log.Info("fetching time entries", slog.Time("from", from), slog.Time("to", to))
+	reportProgress(ctx, "entries", run)
+
+	entries, err := uc.Toggl.ListTimeEntries(ctx, from, to)
 	if err != nil {
 		return err
 	}
-	uc.Log.Info("fetched projects", slog.Int("count", len(projects)))
+	if err := uc.syncEntries(ctx, run, entries); err != nil {
+		return err
+	}
+	if err := uc.markMissingDeleted(ctx, run, from, to, entries); err != nil {
+		return err
+	}
+	return uc.reconcileRunning(ctx, run, entries)
+}
 
-	if len(projects) > 0 {
-		if err := uc.Sink.SyncProjects(ctx, projects); err != nil {
-			return err
+// RunIncremental syncs reference data and the time entries changed since
+// the persisted watermark (the latest Toggl `at` seen so far), then
+// advances the watermark. Without a watermark it bootstraps from the last
+// 24h ending at now. Clients that return ports.ErrSinceUnsupported get
+// the lookback window ending at now re-fetched instead, which leaves the
+// watermark alone.
+func (uc *SyncUseCase) RunIncremental(ctx context.Context, trigger domain.SyncTrigger, now time.Time) (domain.SyncRun, error) {
+	if uc.Toggl == nil || uc.Sink == nil || uc.Watermarks == nil {
+		return domain.SyncRun{}, errors.New("usecase not initialized: missing dependencies")
+	}
+	run := uc.startRun(ctx, trigger, "incremental", now.Add(-24*time.Hour), now)
+	err := uc.runIncremental(ctx, run, now)
+	uc.finishRun(ctx, run, err)
+	return *run, err
+}
+
+func (uc *SyncUseCase) runIncremental(ctx context.Context, run *domain.SyncRun, now time.Time) error {
+	mark, ok, err := uc.Watermarks.LoadWatermark(ctx, uc.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		run.From = mark
+	}
+	if err := uc.syncReferenceData(ctx, run); err != nil {
+		return err
+	}
+
+	reportProgress(ctx, "entries", run)
+	var entries []domain.TimeEntry
+	if ok {
+		uc.Log.Info("fetching time entries changed since watermark", slog.Time("since", mark))
+		entries, err = uc.Toggl.ListTimeEntriesSince(ctx, mark)
+		if errors.Is(err, ports.ErrSinceUnsupported) {
+			return uc.runLookback(ctx, run, now)
 		}
 	} else {
-		uc.Log.Info("no projects to sync")
+		mark = run.From
+		uc.Log.Info("no watermark, fetching time entries", slog.Time("from", mark), slog.Time("to", now))
+		entries, err = uc.Toggl.ListTimeEntries(ctx, mark, now)
+	}
+	if err != nil {
+		return err
+	}
+	if err := uc.syncEntries(ctx, run, entries); err != nil {
+		return err
+	}
+	if !ok {
+		// The bootstrap window was fully fetched; since queries instead
+		// report deletions through DeletedAt.
+		if err := uc.markMissingDeleted(ctx, run, mark, now, entries); err != nil {
+			return err
+		}
+	}
+	if err := uc.reconcileRunning(ctx, run, entries); err != nil {
+		return err
 	}
 
-	uc.Log.Info("fetching time entries", slog.Time("from", from), slog.Time("to", to))
+	next := mark
+	for _, e := range entries {
+		if e.At.After(next) {
+			next = e.At
+		}
+	}
+	if ok && !next.After(mark) {
+		return nil
+	}
+	if err := uc.Watermarks.SaveWatermark(ctx, uc.WorkspaceID, next); err != nil {
+		return err
+	}
+	uc.Log.Info("watermark advanced", slog.Time("watermark", next))
+	return nil
+}
 
-	entries, err := uc.Toggl.ListTimeEntries(ctx, from, to)
+// runLookback re-fetches the entries started within the lookback window
+// ending at now and soft-deletes those missing from it. Edits to entries
+// started earlier are not seen; re-sync them with an explicit window.
+func (uc *SyncUseCase) runLookback(ctx context.Context, run *domain.SyncRun, now time.Time) error {
+	lookback := uc.Lookback
+	if lookback <= 0 {
+		lookback = defaultLookback
+	}
+	from := now.Add(-lookback)
+	run.From = from
+	uc.Log.Info("client cannot list changes, re-fetching lookback window", slog.Time("from", from), slog.Time("to", now))
+	entries, err := uc.Toggl.ListTimeEntries(ctx, from, now)
 	if err != nil {
 		return err
 	}
+	if err := uc.syncEntries(ctx, run, entries); err != nil {
+		return err
+	}
+	if err := uc.markMissingDeleted(ctx, run, from, now, entries); err != nil {
+		return err
+	}
+	return uc.reconcileRunning(ctx, run, entries)
+}
+
+// markMissingDeleted soft-deletes stored entries in a fully fetched window
+// that Toggl no longer returns.
+func (uc *SyncUseCase) markMissingDeleted(ctx context.Context, run *domain.SyncRun, from, to time.Time, entries []domain.TimeEntry) error {
+	reportProgress(ctx, "deletions", run)
+	present := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		if e.DeletedAt == nil {
+			present = append(present, e.ID)
+		}
+	}
+	n, err := uc.Sink.MarkMissingDeleted(ctx, from, to, uc.WorkspaceID, present)
+	if err != nil {
+		return err
+	}
+	counts := run.Counts["entries"]
+	counts.Deleted += n
+	run.Counts["entries"] = counts
+	return nil
+}
+
+// syncReferenceData syncs groups, users, clients, projects, tasks and tags.
+func (uc *SyncUseCase) syncReferenceData(ctx context.Context, run *domain.SyncRun) error {
+	if err := syncEntity(ctx, uc, run, "groups", uc.Toggl.ListGroups, uc.Sink.SyncGroups); err != nil {
+		return err
+	}
+	if err := syncEntity(ctx, uc, run, "users", uc.Toggl.ListUsers, uc.Sink.SyncUsers); err != nil {
+		return err
+	}
+	if err := syncEntity(ctx, uc, run, "clients", uc.Toggl.ListClients, uc.Sink.SyncClients); err != nil {
+		return err
+	}
+	if err := syncEntity(ctx, uc, run, "projects", uc.Toggl.ListProjects, uc.Sink.SyncProjects); err != nil {
+		return err
+	}
+	if err := syncEntity(ctx, uc, run, "tasks", uc.Toggl.ListTasks, uc.Sink.SyncTasks); err != nil {
+		return err
+	}
+	return syncEntity(ctx, uc, run, "tags", uc.Toggl.ListTags, uc.Sink.SyncTags)
+}
+
+// syncEntity fetches one kind of reference data and writes it to the sink,
+// recording the counts under name.
+func syncEntity[T any](
+	ctx context.Context,
+	uc *SyncUseCase,
+	run *domain.SyncRun,
+	name string,
+	list func(context.Context) ([]T, error),
+	sync func(context.Context, []T) (domain.SyncCounts, error),
+) error {
+	uc.Log.Info("fetching " + name)
+	reportProgress(ctx, name, run)
+
+	items, err := list(ctx)
+	if err != nil {
+		return err
+	}
+	uc.Log.Info("fetched "+name, slog.Int("count", len(items)))
+
+	counts := domain.SyncCounts{Fetched: len(items)}
+	if len(items) > 0 {
+		res, err := sync(ctx, items)
+		if err != nil {
+			return err
+		}
+		counts.Add(res)
+	} else {
+		uc.Log.Info("no " + name + " to sync")
+	}
+	run.Counts[name] = counts
+	return nil
+}
+
+// syncEntries writes fetched time entries to the sink.
+func (uc *SyncUseCase) syncEntries(ctx context.Context, run *domain.SyncRun, entries []domain.TimeEntry) error {
 	uc.Log.Info("fetched time entries", slog.Int("count", len(entries)))
 
+	counts := domain.SyncCounts{Fetched: len(entries)}
+	run.Counts["entries"] = counts
 	if len(entries) == 0 {
 		uc.Log.Info("no entries to sync")
 		return nil
 	}
 
-	if err := uc.Sink.SyncEntries(ctx, entries); err != nil {
+	res, err := uc.Sink.SyncEntries(ctx, domain.MarkRunning(entries, run.StartedAt))
+	if err != nil {
 		return err
 	}
-	uc.Log.Info("sync completed", slog.Int("count", len(entries)))
+	counts.Add(res)
+	run.Counts["entries"] = counts
+	uc.Log.Info("entries synced",
+		slog.Int("count", len(entries)),
+		slog.Int("inserted", counts.Inserted),
+		slog.Int("updated", counts.Updated),
+		slog.Int("unchanged", counts.Unchanged),
+	)
 	return nil
 }
+
+// reconcileRunning refetches timers stored as running that this run did
+// not return, so they get their final stop time, or a fresh provisional
+// duration while still running. Timers Toggl no longer returns are left
+// for deletion detection.
+func (uc *SyncUseCase) reconcileRunning(ctx context.Context, run *domain.SyncRun, fetched []domain.TimeEntry) error {
+	if uc.Running == nil {
+		return nil
+	}
+	reportProgress(ctx, "running", run)
+	stored, err := uc.Running.ListRunningEntries(ctx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int64]bool, len(fetched))
+	for _, e := range fetched {
+		seen[e.ID] = true
+	}
+	stale := make(map[int64]bool)
+	var from time.Time
+	for _, e := range stored {
+		if seen[e.ID] {
+			continue
+		}
+		stale[e.ID] = true
+		if from.IsZero() || e.Start.Before(from) {
+			from = e.Start
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	uc.Log.Info("reconciling running timers", slog.Int("count", len(stale)), slog.Time("from", from))
+	entries, err := uc.Toggl.ListTimeEntries(ctx, from, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	var found []domain.TimeEntry
+	for _, e := range entries {
+		if stale[e.ID] {
+			found = append(found, e)
+			delete(stale, e.ID)
+		}
+	}
+	for id := range stale {
+		uc.Log.Warn("running timer not returned by toggl", slog.Int64("entry_id", id))
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	res, err := uc.Sink.SyncEntries(ctx, domain.MarkRunning(found, run.StartedAt))
+	if err != nil {
+		return err
+	}
+	counts := run.Counts["entries"]
+	counts.Fetched += len(found)
+	counts.Add(res)
+	run.Counts["entries"] = counts
+	return nil
+}
+
+// startRun creates the run record. Recording failures are logged but never
+// fail the sync itself.
+func (uc *SyncUseCase) startRun(ctx context.Context, trigger domain.SyncTrigger, mode string, from, to time.Time) *domain.SyncRun {
+	run := &domain.SyncRun{
+		Trigger:   trigger,
+		Mode:      mode,
+		From:      from,
+		To:        to,
+		StartedAt: time.Now().UTC(),
+		Status:    domain.StatusRunning,
+		Counts:    make(map[string]domain.SyncCounts),
+	}
+	if uc.Runs == nil {
+		return run
+	}
+	id, err := uc.Runs.StartRun(ctx, *run)
+	if err != nil {
+		uc.Log.Warn("failed to record sync run start", slog.String("error", err.Error()))
+		return run
+	}
+	run.ID = id
+	return run
+}
+
+// finishRun stores the outcome of run. It uses a fresh context so that a
+// cancelled or timed out sync is still recorded.
+func (uc *SyncUseCase) finishRun(ctx context.Context, run *domain.SyncRun, err error) {
+	run.FinishedAt = time.Now().UTC()
+	run.Status = domain.StatusOK
+	if err != nil {
+		run.Status = domain.StatusError
+		run.Error = err.Error()
+	}
+	if rep, ok := uc.Sink.(ports.SinkReporter); ok {
+		run.Sinks = rep.TakeResults()
+	}
+	if uc.Runs == nil || run.ID == 0 {
+		return
+	}
+	c, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := uc.Runs.FinishRun(c, *run); err != nil {
+		uc.Log.Warn("failed to record sync run result", slog.Int64("run_id", run.ID), slog.String("error", err.Error()))
+	}
+}